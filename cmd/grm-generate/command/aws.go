@@ -13,6 +13,7 @@ package command
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -20,10 +21,11 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/ghodss/yaml"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 
+	"github.com/anydotcloud/grm-generate/pkg/config"
+	"github.com/anydotcloud/grm-generate/pkg/diagram"
 	discover "github.com/anydotcloud/grm-generate/pkg/discover/aws"
 	"github.com/anydotcloud/grm-generate/pkg/model"
 )
@@ -34,9 +36,10 @@ const (
 
 // discoverAWSCmd is the command that discovers AWS resource models
 var discoverAWSCmd = &cobra.Command{
-	Use:   "aws <service>",
-	Short: "Discover resource models for an AWS service API",
-	RunE:  discoverAWS,
+	Use:     "aws <service>",
+	Short:   "Discover resource models for an AWS service API",
+	PreRunE: validateOutput(outputOptions),
+	RunE:    discoverAWS,
 }
 
 func init() {
@@ -55,15 +58,14 @@ func discoverAWS(
 	ctx, cancel := newContext(context.Background())
 	defer cancel()
 
-	sdkRepoTag := ""
-	err := cacheRepo(ctx, optCachePath, awsSDKRepoURL, sdkRepoTag)
+	sdkCachePath, err := cacheAWSSDK(ctx, "")
 	if err != nil {
 		return err
 	}
-	sdkCachePath := filepath.Join(optCachePath, "aws-sdk-go")
 	disco := discover.New(
 		discover.WithCachePath(sdkCachePath),
 		discover.WithServices(svcAlias),
+		discover.WithConfig(config.New(config.WithPath(optConfigPath))),
 	)
 	resources, err := disco.DiscoverResources(ctx)
 	if err != nil {
@@ -72,20 +74,37 @@ func discoverAWS(
 	switch optOutput {
 	case "yaml":
 		return printResourceDefinitionsYAML(os.Stdout, resources)
+	case "json":
+		return printResourceDefinitionsJSON(os.Stdout, resources)
 	case "table":
 		return printResourceDefinitionsTable(os.Stdout, resources)
+	case "dot":
+		return diagram.WriteDot(os.Stdout, resources)
+	case "mermaid":
+		return diagram.WriteMermaid(os.Stdout, resources)
 	}
 	return nil
 }
 
+// cacheAWSSDK ensures that we have a git clone'd copy of the aws-sdk-go
+// repository, optionally checked out at the supplied tag, and returns the path
+// to that clone'd copy
+func cacheAWSSDK(
+	ctx context.Context,
+	sdkRepoTag string, // optional Git tag to checkout
+) (string, error) {
+	err := cacheRepo(ctx, optCachePath, awsSDKRepoURL, sdkRepoTag)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(optCachePath, "aws-sdk-go"), nil
+}
+
 func printResourceDefinitionsYAML(
 	w io.Writer,
 	resources []*model.ResourceDefinition,
 ) error {
-	r := struct {
-		Resources []*model.ResourceDefinition
-	}{resources}
-	y, err := yaml.Marshal(&r)
+	y, err := model.MarshalYAML(resources)
 	if err != nil {
 		return err
 	}
@@ -93,6 +112,29 @@ func printResourceDefinitionsYAML(
 	return err
 }
 
+func printResourceDefinitionsJSON(
+	w io.Writer,
+	resources []*model.ResourceDefinition,
+) error {
+	j, err := model.MarshalJSON(resources)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(j)
+	return err
+}
+
+// printJSON writes the supplied object to the supplied writer as indented
+// JSON. Map keys are always written in sorted order.
+func printJSON(w io.Writer, obj interface{}) error {
+	j, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(j, '\n'))
+	return err
+}
+
 func printResourceDefinitionsTable(
 	w io.Writer,
 	resources []*model.ResourceDefinition,
@@ -0,0 +1,169 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/olekukonko/tablewriter"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/anydotcloud/grm-generate/pkg/config"
+	pkgdiscover "github.com/anydotcloud/grm-generate/pkg/discover"
+	discover "github.com/anydotcloud/grm-generate/pkg/discover/aws"
+)
+
+// coverageCmd is the command that reports on resource discovery coverage
+var coverageCmd = &cobra.Command{
+	Use:   "coverage",
+	Short: "Report on how much of a cloud service API is covered by discovered resources",
+}
+
+// coverageAWSCmd is the command that reports on AWS resource discovery
+// coverage
+var coverageAWSCmd = &cobra.Command{
+	Use:     "aws <service>",
+	Short:   "Report on resource discovery coverage for an AWS service API",
+	PreRunE: validateOutput(reportOutputOptions),
+	RunE:    coverageAWS,
+}
+
+func init() {
+	coverageCmd.PersistentFlags().StringVarP(
+		&optOutput, "output", "o", "table",
+		"Output in what format? One of: "+strings.Join(reportOutputOptions, ", "),
+	)
+	coverageCmd.AddCommand(coverageAWSCmd)
+	rootCmd.AddCommand(coverageCmd)
+}
+
+// coverageAWS reads AWS API definitions and reports which resources were
+// inferred, which operations they claimed and why any were skipped
+func coverageAWS(
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) != 1 {
+		return fmt.Errorf("please specify the service alias for the AWS service API to report on")
+	}
+	svcAlias := strings.ToLower(args[0])
+	ctx, cancel := newContext(context.Background())
+	defer cancel()
+
+	sdkCachePath, err := cacheAWSSDK(ctx, "")
+	if err != nil {
+		return err
+	}
+	reporter := discover.NewCoverageReporter(
+		discover.WithCachePath(sdkCachePath),
+		discover.WithServices(svcAlias),
+		discover.WithConfig(config.New(config.WithPath(optConfigPath))),
+	)
+	coverages, err := reporter.ReportCoverage(ctx)
+	if err != nil {
+		return err
+	}
+	switch optOutput {
+	case "yaml":
+		return printCoverageYAML(os.Stdout, coverages)
+	case "json":
+		return printCoverageJSON(os.Stdout, coverages)
+	case "table":
+		return printCoverageTable(os.Stdout, coverages)
+	}
+	return nil
+}
+
+func printCoverageYAML(
+	w io.Writer,
+	coverages []*pkgdiscover.ServiceCoverage,
+) error {
+	r := struct {
+		Services []*pkgdiscover.ServiceCoverage `json:"services"`
+	}{coverages}
+	y, err := yaml.Marshal(&r)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(y)
+	return err
+}
+
+func printCoverageJSON(
+	w io.Writer,
+	coverages []*pkgdiscover.ServiceCoverage,
+) error {
+	r := struct {
+		Services []*pkgdiscover.ServiceCoverage `json:"services"`
+	}{coverages}
+	return printJSON(w, &r)
+}
+
+func printCoverageTable(
+	w io.Writer,
+	coverages []*pkgdiscover.ServiceCoverage,
+) error {
+	for _, c := range coverages {
+		table := tablewriter.NewWriter(w)
+		headers := []string{
+			"Resource",
+			"Operations",
+			"Status",
+		}
+		table.SetHeader(headers)
+		data := [][]string{}
+		for _, rc := range c.Resources {
+			opTypes := lo.Keys(rc.Operations)
+			sort.Strings(opTypes)
+			ops := make([]string, len(opTypes))
+			for x, opType := range opTypes {
+				ops[x] = fmt.Sprintf("%s: %s", opType, rc.Operations[opType])
+			}
+			status := "discovered"
+			if rc.IsSkipped() {
+				status = "skipped: " + rc.SkipReason
+			}
+			data = append(data, []string{
+				rc.Name, strings.Join(ops, "\n"), status,
+			})
+		}
+		table.SetAutoWrapText(false)
+		table.SetRowLine(true)
+		table.AppendBulk(data)
+		fmt.Fprintf(w, "Service: %s\n", c.Service)
+		table.Render()
+		fmt.Fprintf(
+			w, "Claimed %d of %d API operations (%.2f%%)\n",
+			c.ClaimedOperations, c.TotalOperations, c.ClaimedPercent,
+		)
+		if len(c.OrphanOperations) > 0 {
+			fmt.Fprintf(
+				w, "Orphan operations: %s\n",
+				strings.Join(c.OrphanOperations, ", "),
+			)
+		}
+		if len(c.IgnoredOperations) > 0 {
+			fmt.Fprintf(
+				w, "Ignored operations: %s\n",
+				strings.Join(c.IgnoredOperations, ", "),
+			)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/anydotcloud/grm-generate/pkg/config"
+	"github.com/anydotcloud/grm-generate/pkg/diff"
+	discover "github.com/anydotcloud/grm-generate/pkg/discover/aws"
+	"github.com/anydotcloud/grm-generate/pkg/git"
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+var (
+	optDiffFromTag string
+	optDiffToTag   string
+)
+
+// diffCmd is the command that compares the resource models from two saved
+// discovery runs
+var diffCmd = &cobra.Command{
+	Use:     "diff <old file> <new file>",
+	Short:   "Compare resource models from two discovery runs",
+	PreRunE: validateOutput(reportOutputOptions),
+	RunE:    diffFiles,
+}
+
+// diffAWSCmd is the command that compares the resource models discovered
+// from two different aws-sdk-go versions
+var diffAWSCmd = &cobra.Command{
+	Use:     "aws <service>",
+	Short:   "Compare resource models for an AWS service API between two aws-sdk-go tags",
+	PreRunE: validateOutput(reportOutputOptions),
+	RunE:    diffAWS,
+}
+
+func init() {
+	diffCmd.PersistentFlags().StringVarP(
+		&optOutput, "output", "o", "table",
+		"Output in what format? One of: "+strings.Join(reportOutputOptions, ", "),
+	)
+	diffAWSCmd.Flags().StringVar(
+		&optDiffFromTag, "from-tag", "",
+		"aws-sdk-go Git tag to discover the old resource models from",
+	)
+	diffAWSCmd.Flags().StringVar(
+		&optDiffToTag, "to-tag", "",
+		"aws-sdk-go Git tag to discover the new resource models from",
+	)
+	diffCmd.AddCommand(diffAWSCmd)
+	rootCmd.AddCommand(diffCmd)
+}
+
+// diffFiles compares the resource models in two files written by `discover
+// -o yaml` or `discover -o json`
+func diffFiles(
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) != 2 {
+		return fmt.Errorf("please specify the old and new discovery output files to compare")
+	}
+	oldRDs, err := readResourceDefinitionsFile(args[0])
+	if err != nil {
+		return err
+	}
+	newRDs, err := readResourceDefinitionsFile(args[1])
+	if err != nil {
+		return err
+	}
+	return printChanges(cmd, diff.Compare(oldRDs, newRDs))
+}
+
+// diffAWS discovers the resource models for an AWS service API at two
+// aws-sdk-go tags and compares them. The cached aws-sdk-go repository is left
+// at the branch or commit it was at before the comparison.
+func diffAWS(
+	cmd *cobra.Command,
+	args []string,
+) (err error) {
+	if len(args) != 1 {
+		return fmt.Errorf("please specify the service alias for the AWS service API to compare")
+	}
+	if optDiffFromTag == "" || optDiffToTag == "" {
+		return fmt.Errorf("please specify both --from-tag and --to-tag")
+	}
+	svcAlias := strings.ToLower(args[0])
+	ctx, cancel := newContext(context.Background())
+	defer cancel()
+
+	// Checking out the tags moves the shared aws-sdk-go cache, which other
+	// commands use without a tag, so the cache's previous HEAD is restored
+	// once both tags have been discovered.
+	restore, err := saveAWSSDKHead(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if rerr := restore(); rerr != nil && err == nil {
+			err = rerr
+		}
+	}()
+	cfg := config.New(config.WithPath(optConfigPath))
+	oldRDs, err := discoverAWSAtTag(ctx, svcAlias, optDiffFromTag, cfg)
+	if err != nil {
+		return err
+	}
+	newRDs, err := discoverAWSAtTag(ctx, svcAlias, optDiffToTag, cfg)
+	if err != nil {
+		return err
+	}
+	return printChanges(cmd, diff.Compare(oldRDs, newRDs))
+}
+
+// saveAWSSDKHead ensures the aws-sdk-go repository is cached and returns a
+// function that checks out the branch or commit that is currently checked out
+// in the cached repository
+func saveAWSSDKHead(
+	ctx context.Context,
+) (func() error, error) {
+	sdkCachePath, err := cacheAWSSDK(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	repo, err := git.Open(sdkCachePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open repository: %v", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	return func() error {
+		if err := git.CheckoutRef(ctx, repo, head); err != nil {
+			return fmt.Errorf(
+				"cannot restore %s in %s: %v",
+				head.Name().Short(), sdkCachePath, err,
+			)
+		}
+		return nil
+	}, nil
+}
+
+// discoverAWSAtTag checks out the supplied aws-sdk-go tag and discovers the
+// resource models for an AWS service API
+func discoverAWSAtTag(
+	ctx context.Context,
+	svcAlias string,
+	tag string,
+	cfg *config.Config,
+) ([]*model.ResourceDefinition, error) {
+	sdkCachePath, err := cacheAWSSDK(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+	disco := discover.New(
+		discover.WithCachePath(sdkCachePath),
+		discover.WithServices(svcAlias),
+		discover.WithConfig(cfg),
+	)
+	return disco.DiscoverResources(ctx)
+}
+
+// readResourceDefinitionsFile returns the resource models in the supplied
+// YAML or JSON file
+func readResourceDefinitionsFile(
+	path string,
+) ([]*model.ResourceDefinition, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rds, err := model.Unmarshal(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resources from %s: %s", path, err)
+	}
+	return rds, nil
+}
+
+// printChanges outputs the supplied changes and returns an error if any of
+// the changes are breaking
+func printChanges(
+	cmd *cobra.Command,
+	changes []*diff.Change,
+) error {
+	var err error
+	switch optOutput {
+	case "yaml":
+		err = printChangesYAML(os.Stdout, changes)
+	case "json":
+		err = printJSON(os.Stdout, &struct {
+			Changes []*diff.Change `json:"changes"`
+		}{changes})
+	case "table":
+		err = printChangesTable(os.Stdout, changes)
+	}
+	if err != nil {
+		return err
+	}
+	if diff.HasBreakingChanges(changes) {
+		// The breaking changes were already output above. There is no need
+		// to print the usage information as well.
+		cmd.SilenceUsage = true
+		return fmt.Errorf("found breaking changes")
+	}
+	return nil
+}
+
+func printChangesYAML(
+	w io.Writer,
+	changes []*diff.Change,
+) error {
+	r := struct {
+		Changes []*diff.Change `json:"changes"`
+	}{changes}
+	y, err := yaml.Marshal(&r)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(y)
+	return err
+}
+
+func printChangesTable(
+	w io.Writer,
+	changes []*diff.Change,
+) error {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No changes")
+		return nil
+	}
+	table := tablewriter.NewWriter(w)
+	headers := []string{
+		"Resource",
+		"Field",
+		"Change",
+		"Old",
+		"New",
+		"Breaking?",
+	}
+	table.SetHeader(headers)
+	data := [][]string{}
+	for _, c := range changes {
+		data = append(data, []string{
+			c.Kind.Service + "/" + c.Kind.Name, c.Path, string(c.Type),
+			c.Old, c.New, strconv.FormatBool(c.IsBreaking),
+		})
+	}
+	table.SetAutoMergeCellsByColumnIndex([]int{0})
+	table.SetRowLine(true)
+	table.AppendBulk(data)
+	table.Render()
+	return nil
+}
@@ -12,6 +12,8 @@
 package command
 
 import (
+	"strings"
+
 	"github.com/spf13/cobra"
 )
 
@@ -24,7 +26,7 @@ var discoverCmd = &cobra.Command{
 func init() {
 	discoverCmd.PersistentFlags().StringVarP(
 		&optOutput, "output", "o", "table",
-		"Output in what format?",
+		"Output in what format? One of: "+strings.Join(outputOptions, ", "),
 	)
 }
 
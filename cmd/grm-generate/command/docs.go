@@ -0,0 +1,118 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/anydotcloud/grm-generate/pkg/config"
+	discover "github.com/anydotcloud/grm-generate/pkg/discover/aws"
+	"github.com/anydotcloud/grm-generate/pkg/docs"
+)
+
+var (
+	optDocsFormat     string
+	optDocsOutputPath string
+)
+
+// docsCmd is the command that renders reference documentation for
+// discovered resources
+var docsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Render reference documentation for discovered resource models",
+}
+
+// docsAWSCmd is the command that renders reference documentation for
+// discovered AWS resource models
+var docsAWSCmd = &cobra.Command{
+	Use:   "aws <service>",
+	Short: "Render reference documentation for an AWS service API's resource models",
+	Long: `Render reference documentation for an AWS service API's resource models.
+
+One page is rendered for each resource, describing its kind and field tree,
+along with an index page for the service. Resource identifiers are not yet
+discovered and so are not listed.`,
+	RunE: docsAWS,
+}
+
+func init() {
+	docsCmd.PersistentFlags().StringVar(
+		&optDocsFormat, "format", docs.FormatMarkdown,
+		"Documentation format. One of: "+strings.Join(docs.Formats, ", "),
+	)
+	docsCmd.PersistentFlags().StringVar(
+		&optDocsOutputPath, "output-path", "docs",
+		"Path to the directory to write documentation pages to",
+	)
+	docsCmd.AddCommand(docsAWSCmd)
+	rootCmd.AddCommand(docsCmd)
+}
+
+// docsAWS reads AWS API definitions, discovers resource models and renders a
+// reference documentation page for each resource along with a service index
+// page
+func docsAWS(
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) != 1 {
+		return fmt.Errorf("please specify the service alias for the AWS service API to document")
+	}
+	if !lo.Contains(docs.Formats, optDocsFormat) {
+		return fmt.Errorf(
+			"unsupported documentation format %q. Supported formats are: %s",
+			optDocsFormat, strings.Join(docs.Formats, ", "),
+		)
+	}
+	svcAlias := strings.ToLower(args[0])
+	ctx, cancel := newContext(context.Background())
+	defer cancel()
+
+	sdkCachePath, err := cacheAWSSDK(ctx, "")
+	if err != nil {
+		return err
+	}
+	disco := discover.New(
+		discover.WithCachePath(sdkCachePath),
+		discover.WithServices(svcAlias),
+		discover.WithConfig(config.New(config.WithPath(optConfigPath))),
+	)
+	resources, err := disco.DiscoverResources(ctx)
+	if err != nil {
+		return err
+	}
+	pages, err := docs.RenderPages(optDocsFormat, resources)
+	if err != nil {
+		return err
+	}
+	if optDryRun {
+		for _, page := range pages {
+			fmt.Fprintf(os.Stdout, "==> %s\n", page.Path)
+			os.Stdout.Write(page.Contents)
+		}
+		return nil
+	}
+	if err := docs.WritePages(optDocsOutputPath, pages); err != nil {
+		return err
+	}
+	log.Info(
+		"wrote documentation",
+		"pages", len(pages), "output_path", optDocsOutputPath,
+	)
+	return nil
+}
@@ -0,0 +1,349 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anydotcloud/grm-generate/pkg/config"
+	discover "github.com/anydotcloud/grm-generate/pkg/discover/aws"
+	"github.com/anydotcloud/grm-generate/pkg/generate"
+	generateaws "github.com/anydotcloud/grm-generate/pkg/generate/aws"
+	"github.com/anydotcloud/grm-generate/pkg/git"
+	"github.com/anydotcloud/grm-generate/pkg/model"
+	"github.com/anydotcloud/grm-generate/pkg/version"
+)
+
+var (
+	optGenerateOutputPath   string
+	optGenerateTemplateDirs []string
+	optGeneratePackageBase  string
+	optGenerateAPIVersion   string
+	optGenerateCheck        bool
+	optGenerateTarget       string
+	optGenerateSDKTag       string
+)
+
+// generateCmd is the command that generates code for discovered resources
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate code for discovered resource models",
+}
+
+// generateAWSCmd is the command that generates code for discovered AWS
+// resource models
+var generateAWSCmd = &cobra.Command{
+	Use:   "aws <service>",
+	Short: "Generate code for an AWS service API's resource models",
+	RunE:  generateAWS,
+}
+
+// generateCRDCmd is the command that generates Kubernetes
+// CustomResourceDefinition manifests for discovered resources. It is
+// equivalent to `generate --target crd`.
+var generateCRDCmd = &cobra.Command{
+	Use:   "crd",
+	Short: "Generate Kubernetes CustomResourceDefinition manifests for discovered resource models",
+}
+
+// generateCRDAWSCmd is the command that generates Kubernetes
+// CustomResourceDefinition manifests for discovered AWS resource models
+var generateCRDAWSCmd = &cobra.Command{
+	Use:   "aws <service>",
+	Short: "Generate Kubernetes CustomResourceDefinition manifests for an AWS service API's resource models",
+	RunE:  generateCRDAWS,
+}
+
+func init() {
+	generateCmd.PersistentFlags().StringVar(
+		&optGenerateOutputPath, "output-path", ".",
+		"Path to the directory to write generated files to",
+	)
+	generateCmd.PersistentFlags().StringArrayVar(
+		&optGenerateTemplateDirs, "template-dir", nil,
+		"Path to a directory of Go templates that override or extend the "+
+			"default templates. May be repeated; later directories take "+
+			"precedence",
+	)
+	generateCmd.PersistentFlags().StringVar(
+		&optGeneratePackageBase, "package-base", generate.DefaultPackageBase,
+		"Go import path of the output directory",
+	)
+	generateCmd.PersistentFlags().StringVar(
+		&optGenerateAPIVersion, "api-version", generate.DefaultAPIVersion,
+		"Name of the Go package containing the generated resource types",
+	)
+	generateCmd.PersistentFlags().StringVar(
+		&optGenerateTarget, "target", generate.TargetGo,
+		"What to generate? One of: "+strings.Join(generate.Targets, ", "),
+	)
+	generateCmd.PersistentFlags().BoolVar(
+		&optGenerateCheck, "check", false,
+		"If true, writes nothing and fails if regenerating would change any generated file",
+	)
+	generateAWSCmd.Flags().StringVar(
+		&optGenerateSDKTag, "aws-sdk-go-version", "",
+		"aws-sdk-go Git tag to discover resource models from. Defaults to the currently checked out version",
+	)
+	generateCRDAWSCmd.Flags().StringVar(
+		&optGenerateSDKTag, "aws-sdk-go-version", "",
+		"aws-sdk-go Git tag to discover resource models from. Defaults to the currently checked out version",
+	)
+	generateCRDCmd.AddCommand(generateCRDAWSCmd)
+	generateCmd.AddCommand(generateAWSCmd)
+	generateCmd.AddCommand(generateCRDCmd)
+	rootCmd.AddCommand(generateCmd)
+}
+
+// generateCRDAWS generates Kubernetes CustomResourceDefinition manifests for
+// an AWS service API's resource models
+func generateCRDAWS(
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if cmd.Flags().Changed("target") && optGenerateTarget != generate.TargetCRD {
+		return fmt.Errorf(
+			"--target %s cannot be used with generate crd", optGenerateTarget,
+		)
+	}
+	optGenerateTarget = generate.TargetCRD
+	return generateAWS(cmd, args)
+}
+
+// generateAWS reads AWS API definitions, discovers resource models and
+// generates code for them along with a lock file recording the inputs
+func generateAWS(
+	cmd *cobra.Command,
+	args []string,
+) (err error) {
+	if len(args) != 1 {
+		return fmt.Errorf("please specify the service alias for the AWS service API to generate")
+	}
+	svcAlias := strings.ToLower(args[0])
+	ctx, cancel := newContext(context.Background())
+	defer cancel()
+
+	// Checking out a tag moves the shared aws-sdk-go cache, which other
+	// commands use without a tag, so the cache's previous HEAD is restored
+	// once the code has been generated.
+	restore, err := saveAWSSDKHead(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if rerr := restore(); rerr != nil && err == nil {
+			err = rerr
+		}
+	}()
+	sdkCachePath, err := cacheAWSSDK(ctx, optGenerateSDKTag)
+	if err != nil {
+		return err
+	}
+	cfg := config.New(config.WithPath(optConfigPath))
+	disco := discover.New(
+		discover.WithCachePath(sdkCachePath),
+		discover.WithServices(svcAlias),
+		discover.WithConfig(cfg),
+	)
+	resources, err := disco.DiscoverResources(ctx)
+	if err != nil {
+		return err
+	}
+	sdk, err := getSDKLock(sdkCachePath, awsSDKRepoURL, optGenerateSDKTag)
+	if err != nil {
+		return err
+	}
+	gens := []generate.Generator{}
+	if optGenerateTarget == generate.TargetGo {
+		// The Go resource packages also contain the functions that call the
+		// resources' API operations
+		apis, err := discover.LoadAPIs(
+			ctx,
+			discover.WithCachePath(sdkCachePath),
+			discover.WithServices(svcAlias),
+		)
+		if err != nil {
+			return err
+		}
+		gens = append(gens, generateaws.NewGenerator(
+			apis,
+			generateaws.WithConfig(cfg),
+			generateaws.WithTemplateDirs(optGenerateTemplateDirs...),
+			generateaws.WithAPIVersion(optGenerateAPIVersion),
+		))
+	}
+	return generateFiles(
+		ctx, cmd, cfg, "aws/"+svcAlias, resources, sdk, gens...,
+	)
+}
+
+// generateFiles generates code and a lock file for the supplied resources and
+// either writes the changed generated files, deleting files that are no
+// longer generated, outputs them to stdout or checks that they are up to
+// date. Any supplied cloud provider-specific generators
+// generate files in addition to the files for the target. The supplied
+// service, such as "aws/ecr", and the target identify the generated files in
+// the output directory's manifest and lock file, so that only files
+// previously generated for the same service and target are deleted or
+// checked.
+func generateFiles(
+	ctx context.Context,
+	cmd *cobra.Command,
+	cfg *config.Config,
+	service string,
+	resources []*model.ResourceDefinition,
+	sdk *generate.SDKLock,
+	gens ...generate.Generator,
+) error {
+	gen, err := generate.New(
+		optGenerateTarget,
+		generate.WithConfig(cfg),
+		generate.WithTemplateDirs(optGenerateTemplateDirs...),
+		generate.WithPackageBase(optGeneratePackageBase),
+		generate.WithAPIVersion(optGenerateAPIVersion),
+		generate.WithOutputPath(optGenerateOutputPath),
+	)
+	if err != nil {
+		return err
+	}
+	files, err := gen.Generate(ctx, resources)
+	if err != nil {
+		return err
+	}
+	for _, g := range gens {
+		genFiles, err := g.Generate(ctx, resources)
+		if err != nil {
+			return err
+		}
+		files = append(files, genFiles...)
+	}
+	lock, err := generate.NewLock(
+		version.Version, sdk, optConfigPath, resources,
+	)
+	if err != nil {
+		return err
+	}
+
+	manifestKey := optGenerateTarget + " " + service
+	if optGenerateCheck {
+		return checkGeneratedFiles(cmd, manifestKey, files, lock)
+	}
+
+	lockFile, err := lock.File(optGenerateOutputPath, manifestKey)
+	if err != nil {
+		return err
+	}
+	files = append(files, lockFile)
+	if optDryRun {
+		for _, f := range files {
+			fmt.Fprintf(os.Stdout, "==> %s\n", f.Path)
+			os.Stdout.Write(f.Contents)
+		}
+		return nil
+	}
+	summary, err := generate.SyncFiles(
+		optGenerateOutputPath, manifestKey, files,
+	)
+	if err != nil {
+		return err
+	}
+	for _, change := range []struct {
+		verb  string
+		paths []string
+	}{
+		{"created", summary.Created},
+		{"updated", summary.Updated},
+		{"deleted", summary.Deleted},
+	} {
+		for _, path := range change.paths {
+			fmt.Fprintf(os.Stdout, "%s %s\n", change.verb, path)
+		}
+	}
+	fmt.Fprintln(os.Stdout, summary)
+	log.Info(
+		"generated files",
+		"created", len(summary.Created),
+		"updated", len(summary.Updated),
+		"deleted", len(summary.Deleted),
+		"unchanged", len(summary.Unchanged),
+		"output_path", optGenerateOutputPath,
+	)
+	return nil
+}
+
+// checkGeneratedFiles returns an error if the supplied lock differs from the
+// lock stored under the supplied manifest key in the output directory, if
+// any of the supplied generated files differ from the files in the output
+// directory or if any files previously generated under the manifest key are
+// no longer generated
+func checkGeneratedFiles(
+	cmd *cobra.Command,
+	manifestKey string,
+	files []*generate.File,
+	lock *generate.Lock,
+) error {
+	diffs, err := generate.CheckOutput(
+		optGenerateOutputPath, manifestKey, files, lock,
+	)
+	if err != nil {
+		return err
+	}
+	if len(diffs) == 0 {
+		return nil
+	}
+	for _, diff := range diffs {
+		fmt.Fprintln(os.Stdout, diff)
+	}
+	// The differences were already output above. There is no need to print
+	// the usage information as well.
+	cmd.SilenceUsage = true
+	return fmt.Errorf(
+		"generated files in %s are out of date", optGenerateOutputPath,
+	)
+}
+
+// getSDKLock returns the repository URL, tag and commit of the SDK repository
+// clone'd at the supplied path. If no tag was requested, the first tag
+// pointing at the checked out commit, if any, is used.
+func getSDKLock(
+	repoPath string,
+	repoURL string,
+	tag string,
+) (*generate.SDKLock, error) {
+	repo, err := git.Open(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open repository: %v", err)
+	}
+	commit, err := git.HeadCommit(repo)
+	if err != nil {
+		return nil, err
+	}
+	if tag == "" {
+		tags, err := git.TagsAtCommit(repo, commit)
+		if err != nil {
+			return nil, err
+		}
+		if len(tags) > 0 {
+			tag = tags[0]
+		}
+	}
+	return &generate.SDKLock{
+		Repository: repoURL,
+		Tag:        tag,
+		Commit:     commit,
+	}, nil
+}
@@ -40,6 +40,14 @@ A tool to generate cloud resource manager packages`
 var (
 	outputOptions = []string{
 		"yaml",
+		"json",
+		"table",
+		"dot",
+		"mermaid",
+	}
+	reportOutputOptions = []string{
+		"yaml",
+		"json",
 		"table",
 	}
 )
@@ -47,6 +55,7 @@ var (
 var (
 	defaultCachePath string
 	optCachePath     string
+	optConfigPath    string
 	optDryRun        bool
 	optDebug         bool
 	optOutput        string
@@ -80,6 +89,10 @@ func init() {
 		&optCachePath, "cache-path", defaultCachePath,
 		"Path to directory to store cached files (including clone'd aws-sdk-go repo)",
 	)
+	rootCmd.PersistentFlags().StringVar(
+		&optConfigPath, "config-path", "",
+		"Path to the grm-generate configuration file",
+	)
 }
 
 // setupLogger instantiates the package-level logger
@@ -104,6 +117,23 @@ func setupLogger(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// validateOutput returns a function, suitable for a command's PreRunE, that
+// returns an error if the --output flag is not one of the supplied supported
+// output formats
+func validateOutput(
+	supported []string,
+) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if !lo.Contains(supported, optOutput) {
+			return fmt.Errorf(
+				"unsupported output format %q. Supported formats are: %s",
+				optOutput, strings.Join(supported, ", "),
+			)
+		}
+		return nil
+	}
+}
+
 // customCallerEncoder encodes the caller filepath in a not-too-long,
 // not-too-short way.
 //
@@ -0,0 +1,82 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+const (
+	// BoilerplateYearPlaceholder is replaced with the configured year in the
+	// boilerplate text
+	BoilerplateYearPlaceholder = "${YEAR}"
+	// BoilerplateVendorPlaceholder is replaced with the configured vendor in
+	// the boilerplate text
+	BoilerplateVendorPlaceholder = "${VENDOR}"
+)
+
+// BoilerplateConfig instructs the code generator which license header to
+// write at the top of every generated file. The header is followed by the
+// generated code marker, which is always written.
+//
+// The header text is either supplied inline or read from a file. Lines that
+// are not already Go comments are commented. The `${YEAR}` and `${VENDOR}`
+// placeholders are replaced with the configured year and vendor. A year is
+// required when the text contains the `${YEAR}` placeholder.
+//
+// For example:
+//
+// ```yaml
+// boilerplate:
+//   text: |
+//     Copyright ${YEAR} ${VENDOR}. All rights reserved.
+//   year: 2021
+//   vendor: Example Corp
+// ```
+type BoilerplateConfig struct {
+	// Text is the header text
+	Text string `json:"text,omitempty"`
+	// Path is the path to a file containing the header text. Relative paths
+	// are relative to the directory containing the configuration file, or to
+	// the working directory if the configuration is not read from a file.
+	Path string `json:"path,omitempty"`
+	// Year replaces the `${YEAR}` placeholder. Required when the text
+	// contains the placeholder.
+	Year int `json:"year,omitempty"`
+	// Vendor replaces the `${VENDOR}` placeholder
+	Vendor string `json:"vendor,omitempty"`
+}
+
+// validate returns an error if both or neither of the header text and path
+// are set
+func (c *BoilerplateConfig) validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.Text != "" && c.Path != "" {
+		return fmt.Errorf("boilerplate: only one of text and path may be set")
+	}
+	if c.Text == "" && c.Path == "" {
+		return fmt.Errorf("boilerplate: one of text or path is required")
+	}
+	return nil
+}
+
+// resolvePath makes a relative header file path relative to the supplied
+// directory
+func (c *BoilerplateConfig) resolvePath(dir string) {
+	if c == nil || c.Path == "" || filepath.IsAbs(c.Path) {
+		return
+	}
+	c.Path = filepath.Join(dir, c.Path)
+}
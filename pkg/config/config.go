@@ -14,9 +14,12 @@ package config
 import (
 	"fmt"
 	"io/ioutil"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/ghodss/yaml"
+	"github.com/samber/lo"
 )
 
 var (
@@ -32,6 +35,29 @@ type Config struct {
 	// Resources contains generator instructions for individual CRDs within an
 	// API
 	Resources map[string]*ResourceConfig `json:"resources"`
+	// Ignore contains instructions on which resources, API operations and
+	// fields should be skipped during discovery
+	Ignore *IgnoreConfig `json:"ignore,omitempty"`
+	// Boilerplate contains the license header written at the top of every
+	// generated file
+	Boilerplate *BoilerplateConfig `json:"boilerplate,omitempty"`
+}
+
+// GetBoilerplateConfig returns the BoilerplateConfig, or nil if the config
+// is nil
+func (c *Config) GetBoilerplateConfig() *BoilerplateConfig {
+	if c == nil {
+		return nil
+	}
+	return c.Boilerplate
+}
+
+// GetIgnoreConfig returns the IgnoreConfig, or nil if the config is nil
+func (c *Config) GetIgnoreConfig() *IgnoreConfig {
+	if c == nil {
+		return nil
+	}
+	return c.Ignore
 }
 
 // GetResourceConfigs returns the map, keyed by resource name, of
@@ -44,19 +70,103 @@ func (c *Config) GetResourceConfigs() map[string]*ResourceConfig {
 }
 
 // GetResourceConfig returns a ResourceConfig matching the supplied resource
-// name, using case-insensitive matching.
+// name, using case-insensitive matching. The supplied name may also match one
+// of the resource's renames.
 func (c *Config) GetResourceConfig(search string) *ResourceConfig {
 	if c == nil {
 		return nil
 	}
+	if key := c.getResourceConfigKey(c.GetResourceName(search)); key != "" {
+		return c.Resources[key]
+	}
+	return nil
+}
+
+// GetResourceName returns the name of the resource configured as a rename of
+// the supplied (inferred) resource name, using case-insensitive matching. If
+// the supplied name is not renamed, it is returned unchanged.
+//
+// For example, assume the following configuration snippet:
+//
+// ```yaml
+// resources:
+//
+//	ClusterParameterGroup:
+//	  renames:
+//	    - DBClusterParameterGroup
+//
+// ```
+//
+// Calling GetResourceName("DBClusterParameterGroup") would return
+// "ClusterParameterGroup".
+func (c *Config) GetResourceName(search string) string {
+	if c == nil || c.getResourceConfigKey(search) != "" {
+		return search
+	}
 	for name, rc := range c.Resources {
-		if strings.EqualFold(name, search) {
-			return rc
+		for _, rename := range rc.Renames {
+			if strings.EqualFold(rename, search) {
+				return name
+			}
+		}
+	}
+	return search
+}
+
+// validate returns an error if the configuration is malformed
+func (c *Config) validate() error {
+	if err := c.Ignore.validate(); err != nil {
+		return err
+	}
+	if err := c.Boilerplate.validate(); err != nil {
+		return err
+	}
+	// renamedBy is a map, keyed by lowercased original resource name, of
+	// the resource name that renames that original resource
+	renamedBy := map[string]string{}
+	resNames := lo.Keys(c.Resources)
+	sort.Strings(resNames)
+	for _, resName := range resNames {
+		rc := c.Resources[resName]
+		if err := rc.validateRenames(); err != nil {
+			return fmt.Errorf("resource %s: %s", resName, err)
+		}
+		if err := rc.GetStatusConfig().validate(); err != nil {
+			return fmt.Errorf("resource %s: %s", resName, err)
+		}
+		for _, rename := range rc.Renames {
+			if other := c.getResourceConfigKey(rename); other != "" {
+				return fmt.Errorf(
+					"resource %s renames %s, which is configured as "+
+						"a separate resource",
+					resName, other,
+				)
+			}
+			key := strings.ToLower(rename)
+			if other, found := renamedBy[key]; found && other != resName {
+				return fmt.Errorf(
+					"resources %s and %s both rename %s",
+					other, resName, rename,
+				)
+			}
+			renamedBy[key] = resName
 		}
 	}
 	return nil
 }
 
+// getResourceConfigKey returns the key in the Resources map matching the
+// supplied resource name, using case-insensitive matching, or the empty
+// string if no such key exists
+func (c *Config) getResourceConfigKey(search string) string {
+	for name := range c.Resources {
+		if strings.EqualFold(name, search) {
+			return name
+		}
+	}
+	return ""
+}
+
 // New returns a new Config object given a supplied
 // path to a config file
 func New(
@@ -88,5 +198,15 @@ func New(
 			),
 		)
 	}
+	if err = c.validate(); err != nil {
+		panic(
+			fmt.Sprintf(
+				"failed to validate configuration: %s", err,
+			),
+		)
+	}
+	if len(merged.yaml) == 0 {
+		c.Boilerplate.resolvePath(filepath.Dir(merged.path))
+	}
 	return &c
 }
@@ -12,9 +12,12 @@
 package config_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/anydotcloud/grm-generate/pkg/config"
 )
@@ -125,3 +128,254 @@ func TestGetResourceConfig(t *testing.T) {
 		assert.Equal(test.exp, test.cfg.GetResourceConfig(test.resName))
 	}
 }
+
+func TestGetResourceName(t *testing.T) {
+	assert := assert.New(t)
+	cfg := config.New(
+		config.WithYAML(`
+resources:
+  ClusterParameterGroup:
+    renames:
+      - DBClusterParameterGroup
+    plural_name: ClusterParamGroups
+  Registry:
+    renames:
+      - RegistryPolicy
+      - RegistryScanningConfiguration
+`,
+		),
+	)
+	tests := []struct {
+		name    string
+		resName string
+		cfg     *config.Config
+		exp     string
+	}{
+		{
+			"Nil config returns supplied name",
+			"DBClusterParameterGroup",
+			nil,
+			"DBClusterParameterGroup",
+		},
+		{
+			"Renamed resource returns new name",
+			"DBClusterParameterGroup",
+			cfg,
+			"ClusterParameterGroup",
+		},
+		{
+			"Case-insensitive match of renamed resource returns new name",
+			"dbclusterparametergroup",
+			cfg,
+			"ClusterParameterGroup",
+		},
+		{
+			"Multiple renames collapse into one resource",
+			"RegistryScanningConfiguration",
+			cfg,
+			"Registry",
+		},
+		{
+			"Configured resource name is returned unchanged",
+			"Registry",
+			cfg,
+			"Registry",
+		},
+		{
+			"Resource that is not renamed returns supplied name",
+			"DBCluster",
+			cfg,
+			"DBCluster",
+		},
+	}
+	for _, test := range tests {
+		assert.Equal(
+			test.exp, test.cfg.GetResourceName(test.resName), test.name,
+		)
+	}
+
+	rc := cfg.GetResourceConfig("DBClusterParameterGroup")
+	assert.NotNil(rc)
+	assert.Equal("ClusterParamGroups", *rc.PluralName)
+}
+
+func TestConflictingResourceRenamesPanic(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{
+			"two resources rename the same resource",
+			`
+resources:
+  Registry:
+    renames:
+      - RegistryPolicy
+  Policy:
+    renames:
+      - registrypolicy
+`,
+		},
+		{
+			"resource renames another configured resource",
+			`
+resources:
+  Registry:
+    renames:
+      - RegistryPolicy
+  RegistryPolicy:
+    fields:
+      PolicyText:
+        type: string
+`,
+		},
+	}
+	for _, test := range tests {
+		assert.Panics(
+			func() {
+				config.New(config.WithYAML(test.yaml))
+			},
+			test.name,
+		)
+	}
+}
+
+func TestInvalidStatusConfigPanic(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{
+			"missing status field path",
+			`
+resources:
+  Table:
+    status:
+      ready_values:
+        - ACTIVE
+`,
+		},
+		{
+			"status value is both ready and terminal",
+			`
+resources:
+  Table:
+    status:
+      path: TableStatus
+      ready_values:
+        - ACTIVE
+      terminal_values:
+        - DELETING
+        - ACTIVE
+`,
+		},
+	}
+	for _, test := range tests {
+		assert.Panics(
+			func() {
+				config.New(config.WithYAML(test.yaml))
+			},
+			test.name,
+		)
+	}
+
+	cfg := config.New(config.WithYAML(`
+resources:
+  Table:
+    status:
+      path: TableStatus
+      ready_values:
+        - ACTIVE
+`))
+	sc := cfg.GetResourceConfig("Table").GetStatusConfig()
+	assert.NotNil(sc)
+	assert.Equal("TableStatus", sc.Path)
+	assert.Equal([]string{"ACTIVE"}, sc.ReadyValues)
+}
+
+func TestInvalidBoilerplateConfigPanic(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{
+			"missing boilerplate text and path",
+			`
+boilerplate:
+  vendor: Example Corp
+`,
+		},
+		{
+			"both boilerplate text and path",
+			`
+boilerplate:
+  text: Copyright ${YEAR} ${VENDOR}
+  path: boilerplate.txt
+`,
+		},
+	}
+	for _, test := range tests {
+		assert.Panics(
+			func() {
+				config.New(config.WithYAML(test.yaml))
+			},
+			test.name,
+		)
+	}
+
+	cfg := config.New(config.WithYAML(`
+boilerplate:
+  text: Copyright ${YEAR} ${VENDOR}
+  year: 2022
+  vendor: Example Corp
+`))
+	bc := cfg.GetBoilerplateConfig()
+	assert.NotNil(bc)
+	assert.Equal("Copyright ${YEAR} ${VENDOR}", bc.Text)
+	assert.Equal(2022, bc.Year)
+	assert.Equal("Example Corp", bc.Vendor)
+	assert.Nil(config.New().GetBoilerplateConfig())
+}
+
+func TestBoilerplateConfigPath(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name string
+		path string
+		exp  string
+	}{
+		{
+			"relative to the configuration file",
+			"header.txt",
+			filepath.Join(dir, "header.txt"),
+		},
+		{
+			"absolute",
+			filepath.Join(os.TempDir(), "header.txt"),
+			filepath.Join(os.TempDir(), "header.txt"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfgPath := filepath.Join(dir, "generator.yaml")
+			require.Nil(t, os.WriteFile(
+				cfgPath,
+				[]byte("boilerplate:\n  path: "+test.path+"\n"),
+				0o644,
+			))
+			cfg := config.New(config.WithPath(cfgPath))
+			assert.Equal(t, test.exp, cfg.GetBoilerplateConfig().Path)
+		})
+	}
+
+	// Inline configuration is not read from a file, so the path stays
+	// relative to the working directory
+	cfg := config.New(config.WithYAML(`
+boilerplate:
+  path: header.txt
+`))
+	assert.Equal(t, "header.txt", cfg.GetBoilerplateConfig().Path)
+}
@@ -35,9 +35,29 @@ type FieldConfig struct {
 	// Any time the generator sees the name "Bucket", it will automatically
 	// know that the "Name" field is what should be referred to.
 	Renames []string `json:"renames,omitempty"`
+	// From instructs the code generator to build the field's definition from
+	// a member shape in an API operation's Output or Input shape. This is
+	// useful for custom fields that are not inferred from the Create Input
+	// shape.
+	//
+	// For example, suppose we want the ECR Repository resource to have a
+	// field called RepositoryURI, which is only returned in the
+	// CreateRepository Output shape's Repository member. We could do the
+	// following:
+	//
+	// ```yaml
+	// resources:
+	//   Repository:
+	//     fields:
+	//       RepositoryURI:
+	//         from:
+	//           operation: CreateRepository
+	//           path: Repository.RepositoryUri
+	// ```
+	From *SourceFieldConfig `json:"from,omitempty"`
 	// Type *overrides* the type of the field. This is required for custom
 	// fields that are not inferred either as a Create Input/Output shape or
-	// via the SourceFieldConfig attribute.
+	// via the From (SourceFieldConfig) attribute.
 	//
 	// As an example, assume you have a Role resource where you want to add a
 	// custom field called Policies that is a slice of string pointers.
@@ -72,10 +92,40 @@ type FieldConfig struct {
 	// IsImmutable instructs the code generator to treat the field as immutable
 	// after resource is initially created.
 	IsImmutable *bool `json:"is_immutable,omitempty"`
+	// IsSet instructs the code generator that the order of the elements of
+	// this list field is not significant. Generated comparison code compares
+	// the elements of the old and new values regardless of order.
+	//
+	// ```yaml
+	// resources:
+	//   SecurityGroup:
+	//     fields:
+	//       IpPermissions:
+	//         is_set: true
+	// ```
+	IsSet *bool `json:"is_set,omitempty"`
 	// AWS returns the AWS-specific field configuration
 	AWS *AWSFieldConfig `json:"aws,omitempty"`
 }
 
+// IsCustom returns true if the field configuration contains enough
+// information to construct a field that was not inferred from the API, i.e.
+// the field config has a From or a Type attribute
+func (c *FieldConfig) IsCustom() bool {
+	return c != nil && (c.From != nil || c.Type != nil)
+}
+
+// SourceFieldConfig instructs the code generator where to find the shape that
+// a field's definition should be built from.
+type SourceFieldConfig struct {
+	// Operation is the ID of the API operation containing the shape
+	Operation string `json:"operation"`
+	// Path is the field path to the member shape within the operation's
+	// Output shape or, if not found there, Input shape. For example,
+	// "Repository.RepositoryUri".
+	Path string `json:"path"`
+}
+
 // ForAWS returns the AWS-specific field configuration
 func (c *FieldConfig) ForAWS() *AWSFieldConfig {
 	if c != nil && c.AWS != nil {
@@ -0,0 +1,168 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/anydotcloud/grm/pkg/path/fieldpath"
+)
+
+// IgnoreConfig instructs grm-generate to skip certain resources, API
+// operations and fields during discovery.
+//
+// Every entry is a case-insensitive glob pattern. The `*` wildcard matches any
+// sequence of characters, `?` matches any single character and `[...]`
+// matches a character class.
+//
+// For example, the following configuration snippet ignores the ECR
+// PullThroughCacheRule resource, every EC2 operation that begins with
+// "Import", the Repository resource's ImageScanningConfiguration field and
+// the Tags field of every resource:
+//
+// ```yaml
+// ignore:
+//
+//	resources:
+//	  - PullThroughCacheRule
+//	operations:
+//	  - Import*
+//	field_paths:
+//	  - Repository.ImageScanningConfiguration
+//	  - "*.Tags"
+//
+// ```
+type IgnoreConfig struct {
+	// Resources contains glob patterns matching the names of resources that
+	// should not be discovered.
+	Resources []string `json:"resources,omitempty"`
+	// Operations contains glob patterns matching the IDs of API operations
+	// that should not be associated with any resource.
+	Operations []string `json:"operations,omitempty"`
+	// FieldPaths contains glob patterns matching field paths, prefixed with
+	// the resource name, of fields that should not be added to a resource.
+	// Each dot-separated part of the pattern is matched against the
+	// corresponding part of the field path, so `*` never matches across a
+	// ".". Ignoring a field also ignores all of that field's nested fields.
+	FieldPaths []string `json:"field_paths,omitempty"`
+}
+
+// validate returns an error if any of the ignore patterns are malformed
+func (c *IgnoreConfig) validate() error {
+	if c == nil {
+		return nil
+	}
+	for attr, patterns := range map[string][]string{
+		"resources":   c.Resources,
+		"operations":  c.Operations,
+		"field_paths": c.FieldPaths,
+	} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf(
+					"invalid pattern %q in config 'ignore.%s': %s",
+					pattern, attr, err,
+				)
+			}
+		}
+	}
+	return nil
+}
+
+// IsResourceIgnored returns true if the supplied resource name matches any of
+// the ignored resource patterns
+func (c *IgnoreConfig) IsResourceIgnored(name string) bool {
+	if c == nil {
+		return false
+	}
+	for _, pattern := range c.Resources {
+		if globMatchFold(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOperationIgnored returns true if the supplied API operation ID matches any
+// of the ignored operation patterns
+func (c *IgnoreConfig) IsOperationIgnored(opID string) bool {
+	if c == nil {
+		return false
+	}
+	for _, pattern := range c.Operations {
+		if globMatchFold(pattern, opID) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFieldPathIgnored returns true if the supplied field path for the named
+// resource matches any of the ignored field path patterns
+func (c *IgnoreConfig) IsFieldPathIgnored(
+	resName string,
+	p *fieldpath.Path,
+) bool {
+	if c == nil || p == nil {
+		return false
+	}
+	subject := append([]string{resName}, strings.Split(p.String(), ".")...)
+	for _, pattern := range c.FieldPaths {
+		parts := strings.Split(pattern, ".")
+		if len(parts) != len(subject) {
+			continue
+		}
+		matched := true
+		for x, part := range parts {
+			if !globMatchFold(part, subject[x]) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFieldPathOrContainerIgnored returns true if the supplied field path for
+// the named resource, or the path to any of its containing fields, matches
+// any of the ignored field path patterns. Fields are usually discovered from
+// their containing field, so an ignored field's nested fields are never
+// visited. Fields declared in configuration are not, and use this method to
+// skip the nested fields of an ignored field.
+func (c *IgnoreConfig) IsFieldPathOrContainerIgnored(
+	resName string,
+	p *fieldpath.Path,
+) bool {
+	if c == nil || p == nil {
+		return false
+	}
+	for x := 0; x < p.Size(); x++ {
+		if c.IsFieldPathIgnored(resName, p.CopyAt(x)) {
+			return true
+		}
+	}
+	return false
+}
+
+// globMatchFold returns true if the supplied subject matches the supplied glob
+// pattern, using case-insensitive matching. Malformed patterns never match.
+func globMatchFold(pattern string, subject string) bool {
+	matched, err := path.Match(
+		strings.ToLower(pattern), strings.ToLower(subject),
+	)
+	return err == nil && matched
+}
@@ -0,0 +1,285 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package config_test
+
+import (
+	"testing"
+
+	"github.com/anydotcloud/grm/pkg/path/fieldpath"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/anydotcloud/grm-generate/pkg/config"
+)
+
+var (
+	ignoreConfig = config.New(
+		config.WithYAML(`
+ignore:
+  resources:
+    - PullThroughCacheRule
+    - Registry*
+  operations:
+    - Import*
+    - DescribeImages
+  field_paths:
+    - Repository.ImageScanningConfiguration
+    - Repository.EncryptionConfiguration.*
+    - "*.Tags"
+`,
+		),
+	)
+)
+
+func TestIsResourceIgnored(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name    string
+		resName string
+		cfg     *config.Config
+		exp     bool
+	}{
+		{
+			"Nil config ignores nothing",
+			"PullThroughCacheRule",
+			nil,
+			false,
+		},
+		{
+			"Empty config ignores nothing",
+			"PullThroughCacheRule",
+			emptyConfig,
+			false,
+		},
+		{
+			"Exact match is ignored",
+			"PullThroughCacheRule",
+			ignoreConfig,
+			true,
+		},
+		{
+			"Case-insensitive match is ignored",
+			"pullthroughcacherule",
+			ignoreConfig,
+			true,
+		},
+		{
+			"Glob match is ignored",
+			"RegistryPolicy",
+			ignoreConfig,
+			true,
+		},
+		{
+			"No match is not ignored",
+			"Repository",
+			ignoreConfig,
+			false,
+		},
+	}
+	for _, test := range tests {
+		assert.Equal(
+			test.exp,
+			test.cfg.GetIgnoreConfig().IsResourceIgnored(test.resName),
+			test.name,
+		)
+	}
+}
+
+func TestIsOperationIgnored(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name string
+		opID string
+		cfg  *config.Config
+		exp  bool
+	}{
+		{
+			"Nil config ignores nothing",
+			"ImportImage",
+			nil,
+			false,
+		},
+		{
+			"Glob match is ignored",
+			"ImportImage",
+			ignoreConfig,
+			true,
+		},
+		{
+			"Exact match is ignored",
+			"DescribeImages",
+			ignoreConfig,
+			true,
+		},
+		{
+			"Prefix of exact match is not ignored",
+			"DescribeImage",
+			ignoreConfig,
+			false,
+		},
+	}
+	for _, test := range tests {
+		assert.Equal(
+			test.exp,
+			test.cfg.GetIgnoreConfig().IsOperationIgnored(test.opID),
+			test.name,
+		)
+	}
+}
+
+func TestIsFieldPathIgnored(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name      string
+		resName   string
+		fieldPath string
+		cfg       *config.Config
+		exp       bool
+	}{
+		{
+			"Nil config ignores nothing",
+			"Repository",
+			"Tags",
+			nil,
+			false,
+		},
+		{
+			"Exact match is ignored",
+			"Repository",
+			"ImageScanningConfiguration",
+			ignoreConfig,
+			true,
+		},
+		{
+			"Exact match for another resource is not ignored",
+			"Bucket",
+			"ImageScanningConfiguration",
+			ignoreConfig,
+			false,
+		},
+		{
+			"Nested path of exact match is not matched",
+			"Repository",
+			"ImageScanningConfiguration.ScanOnPush",
+			ignoreConfig,
+			false,
+		},
+		{
+			"Glob on resource name is ignored",
+			"Bucket",
+			"tags",
+			ignoreConfig,
+			true,
+		},
+		{
+			"Glob on nested field is ignored",
+			"Repository",
+			"EncryptionConfiguration.KMSKey",
+			ignoreConfig,
+			true,
+		},
+		{
+			"Glob does not match across path parts",
+			"Repository",
+			"EncryptionConfiguration",
+			ignoreConfig,
+			false,
+		},
+	}
+	for _, test := range tests {
+		path := fieldpath.FromString(test.fieldPath)
+		assert.Equal(
+			test.exp,
+			test.cfg.GetIgnoreConfig().IsFieldPathIgnored(test.resName, path),
+			test.name,
+		)
+	}
+}
+
+func TestIsFieldPathOrContainerIgnored(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name      string
+		resName   string
+		fieldPath string
+		cfg       *config.Config
+		exp       bool
+	}{
+		{
+			"Nil config ignores nothing",
+			"Repository",
+			"Tags.Value",
+			nil,
+			false,
+		},
+		{
+			"Exact match is ignored",
+			"Repository",
+			"ImageScanningConfiguration",
+			ignoreConfig,
+			true,
+		},
+		{
+			"Nested path of exact match is ignored",
+			"Repository",
+			"ImageScanningConfiguration.ScanOnPush",
+			ignoreConfig,
+			true,
+		},
+		{
+			"Nested path of glob match is ignored",
+			"Bucket",
+			"Tags.Value",
+			ignoreConfig,
+			true,
+		},
+		{
+			"Containing field of ignored nested field is not ignored",
+			"Repository",
+			"EncryptionConfiguration",
+			ignoreConfig,
+			false,
+		},
+		{
+			"Unrelated field is not ignored",
+			"Repository",
+			"RepositoryName",
+			ignoreConfig,
+			false,
+		},
+	}
+	for _, test := range tests {
+		path := fieldpath.FromString(test.fieldPath)
+		assert.Equal(
+			test.exp,
+			test.cfg.GetIgnoreConfig().IsFieldPathOrContainerIgnored(
+				test.resName, path,
+			),
+			test.name,
+		)
+	}
+}
+
+func TestInvalidIgnorePatternPanics(t *testing.T) {
+	assert := assert.New(t)
+	assert.Panics(
+		func() {
+			config.New(
+				config.WithYAML(`
+ignore:
+  operations:
+    - "Describe[Images"
+`,
+				),
+			)
+		},
+	)
+}
@@ -12,20 +12,79 @@
 package config
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/anydotcloud/grm/pkg/path/fieldpath"
+	"github.com/samber/lo"
 )
 
 // ResourceConfig represents instructions to grm-generate on how to deal with a
 // particular resource.
 type ResourceConfig struct {
+	// IsCustom instructs the code generator that this resource is not
+	// inferred from the API's operations and is instead declared entirely in
+	// configuration. Any field of a custom resource that is not inferred
+	// from an API operation must have either a `from` or a `type` attribute
+	// in its field configuration.
+	//
+	// ```yaml
+	// resources:
+	//   RepositoryPolicy:
+	//     is_custom: true
+	//     fields:
+	//       RepositoryName:
+	//         from:
+	//           operation: GetRepositoryPolicy
+	//           path: RepositoryName
+	//       PolicyText:
+	//         type: string
+	// ```
+	IsCustom bool `json:"is_custom,omitempty"`
+	// Renames instructs the code generator to consider the resource to be a
+	// rename of one or more inferred resource names. The API operations
+	// inferred for each of the renamed resource names are collapsed into
+	// this single resource.
+	//
+	// For example, suppose the RDS API operations CreateDBClusterParameterGroup
+	// and DescribeDBClusterParameterGroups infer a resource named
+	// DBClusterParameterGroup. If we wanted the resource to be called
+	// ClusterParameterGroup instead, we could do the following:
+	//
+	// ```yaml
+	// resources:
+	//   ClusterParameterGroup:
+	//     renames:
+	//       - DBClusterParameterGroup
+	// ```
+	//
+	// If more than one of the renamed resource names have an API operation of
+	// the same type, use the `aws.operations` configuration to choose which
+	// API operation is used.
+	Renames []string `json:"renames,omitempty"`
+	// PluralName *overrides* the pluralized name of the resource, which is
+	// otherwise inferred from the resource name
+	PluralName *string `json:"plural_name,omitempty"`
+	// Status instructs the code generator how to determine whether the
+	// resource is ready, has failed or may no longer be modified. If not
+	// set, the rules are inferred from a status-like enum field, if any.
+	Status *StatusConfig `json:"status,omitempty"`
 	// Fields contains a map, keyed by field path, of field configurations
 	Fields map[string]*FieldConfig `json:"fields"`
 	// AWS returns the AWS-specific resource configuration
 	AWS *AWSResourceConfig `json:"aws,omitempty"`
 }
 
+// GetStatusConfig returns the resource's status configuration, or nil if
+// there is none
+func (c *ResourceConfig) GetStatusConfig() *StatusConfig {
+	if c == nil {
+		return nil
+	}
+	return c.Status
+}
+
 // GetFieldConfigs returns a map, keyed by field path, of field configurations
 func (c *ResourceConfig) GetFieldConfigs() map[string]*FieldConfig {
 	if c == nil || len(c.Fields) == 0 {
@@ -36,9 +95,11 @@ func (c *ResourceConfig) GetFieldConfigs() map[string]*FieldConfig {
 
 // GetFieldConfig returns the FieldConfig for a specified field path. This
 // method uses case-insensitive matching AND takes into account any renames
-// that a field might have. If the supplied path matched a renamed field,
-// returns the *renamed* field path as the second return value. If the field is
-// not renamed, nil is returned for the second return value.
+// that a field, or any of the field's containing fields, might have. If the
+// supplied path contained a renamed field, returns the *renamed* field path as
+// the second return value. If no part of the field path is renamed, nil is
+// returned for the second return value. Field configurations may be keyed by
+// either the original or the renamed names of the field's containing fields.
 //
 // For example, assume the following configuration snippet:
 //
@@ -50,36 +111,168 @@ func (c *ResourceConfig) GetFieldConfigs() map[string]*FieldConfig {
 //	    Name:
 //	      renames:
 //	        - Bucket
+//	    Encryption:
+//	      renames:
+//	        - EncryptionConfiguration
+//	    Encryption.KMSKeyID:
+//	      renames:
+//	        - KmsKey
 //
 // ```
 //
 // Calling Bucket ResourceConfig's GetFieldConfig("Bucket") would return
 // the FieldConfig struct for the "Name" field, since it has renames for
-// "Bucket" along with a fieldpath.FromString("Name")
+// "Bucket" along with a fieldpath.FromString("Name"). Calling
+// GetFieldConfig("EncryptionConfiguration.KmsKey") would return the
+// FieldConfig struct for the "Encryption.KMSKeyID" field along with a
+// fieldpath.FromString("Encryption.KMSKeyID"). Calling
+// GetFieldConfig("Bucket.Region") would return a nil FieldConfig, since there
+// is no configuration for the "Name.Region" field, along with a
+// fieldpath.FromString("Name.Region").
 func (c *ResourceConfig) GetFieldConfig(
 	path *fieldpath.Path,
 ) (*FieldConfig, *fieldpath.Path) {
 	if c == nil || len(c.Fields) == 0 {
 		return nil, nil
 	}
-	// First try a simple match on the whole stringified path...
-	pathString := path.String()
+	// Field configurations may be keyed by original or renamed field names,
+	// so both the supplied path and the configured field paths are
+	// normalized to their renamed forms before matching.
+	repath, renamed := c.renameFieldPath(path.String())
+	fc := c.getFieldConfigNormalized(repath)
+	if !renamed {
+		return fc, nil
+	}
+	return fc, fieldpath.FromString(repath)
+}
+
+// renameFieldPath returns the supplied stringified field path with each
+// renamed part replaced by its new name, and whether any part was renamed.
+// Parts that are not renamed are preserved.
+func (c *ResourceConfig) renameFieldPath(pathStr string) (string, bool) {
+	renamed := false
+	origParts := []string{}
+	newParts := []string{}
+	for _, part := range strings.Split(pathStr, ".") {
+		newPart := c.getRename(origParts, newParts, part)
+		if newPart != "" {
+			renamed = true
+		} else {
+			newPart = part
+		}
+		origParts = append(origParts, part)
+		newParts = append(newParts, newPart)
+	}
+	return strings.Join(newParts, "."), renamed
+}
+
+// getFieldConfigNormalized returns the FieldConfig whose field path, once
+// renamed, matches the supplied renamed stringified field path, using
+// case-insensitive matching, or nil if no such FieldConfig exists.
+func (c *ResourceConfig) getFieldConfigNormalized(repath string) *FieldConfig {
 	for searchPath, fc := range c.Fields {
-		if strings.EqualFold(pathString, searchPath) {
-			return fc, nil
+		normalized, _ := c.renameFieldPath(searchPath)
+		if strings.EqualFold(repath, normalized) {
+			return fc
 		}
 	}
-	// Now check to see if there are any renames for each part of the supplied
-	// path
-	front := path.Front()
+	return nil
+}
+
+// getRename returns the new name of a supplied field path part, or the empty
+// string if the part is not renamed. The containing field path is supplied in
+// both its original and renamed forms and either may be used as the parent
+// path in the renamed field's configuration.
+func (c *ResourceConfig) getRename(
+	origParentParts []string,
+	newParentParts []string,
+	part string,
+) string {
+	origParent := strings.Join(origParentParts, ".")
+	newParent := strings.Join(newParentParts, ".")
 	for pathStr, fc := range c.Fields {
+		parent, name := splitFieldPath(pathStr)
+		if !strings.EqualFold(parent, newParent) &&
+			!strings.EqualFold(parent, origParent) {
+			continue
+		}
 		for _, rename := range fc.Renames {
-			if strings.EqualFold(front, rename) {
-				return fc, fieldpath.FromString(pathStr)
+			_, renameName := splitFieldPath(rename)
+			if strings.EqualFold(renameName, part) {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// validateRenames returns an error if any renames in the field configurations
+// are malformed or conflict with each other. A rename conflicts with another
+// if both renamed fields have the same containing field path and rename the
+// same original field name. Containing field paths are compared in their
+// renamed forms, so either original or renamed containing field names may be
+// used. Two field configurations also conflict if their field paths are the
+// same once renamed.
+func (c *ResourceConfig) validateRenames() error {
+	if c == nil {
+		return nil
+	}
+	// renamedBy is a map, keyed by lowercased renamed containing field path
+	// plus lowercased original field name, of the field path that renames
+	// that original field
+	renamedBy := map[string]string{}
+	// configuredBy is a map, keyed by lowercased renamed field path, of the
+	// field path of the configuration for that field
+	configuredBy := map[string]string{}
+	pathStrs := lo.Keys(c.Fields)
+	sort.Strings(pathStrs)
+	for _, pathStr := range pathStrs {
+		normalized, _ := c.renameFieldPath(pathStr)
+		key := strings.ToLower(normalized)
+		if other, found := configuredBy[key]; found {
+			return fmt.Errorf(
+				"fields %s and %s both configure field %s",
+				other, pathStr, normalized,
+			)
+		}
+		configuredBy[key] = pathStr
+	}
+	for _, pathStr := range pathStrs {
+		parent, _ := splitFieldPath(pathStr)
+		parent, _ = c.renameFieldPath(parent)
+		for _, rename := range c.Fields[pathStr].Renames {
+			renameParent, renameName := splitFieldPath(rename)
+			renameParent, _ = c.renameFieldPath(renameParent)
+			if strings.Contains(rename, ".") &&
+				!strings.EqualFold(renameParent, parent) {
+				return fmt.Errorf(
+					"rename %s of field %s must have the same "+
+						"containing field path as the field",
+					rename, pathStr,
+				)
 			}
+			key := strings.ToLower(parent + "." + renameName)
+			if other, found := renamedBy[key]; found && other != pathStr {
+				return fmt.Errorf(
+					"fields %s and %s both rename %s",
+					other, pathStr, rename,
+				)
+			}
+			renamedBy[key] = pathStr
 		}
 	}
-	return nil, nil
+	return nil
+}
+
+// splitFieldPath splits a stringified field path into its containing field
+// path and its last part. The containing field path is empty for top-level
+// fields.
+func splitFieldPath(pathStr string) (string, string) {
+	idx := strings.LastIndex(pathStr, ".")
+	if idx == -1 {
+		return "", pathStr
+	}
+	return pathStr[:idx], pathStr[idx+1:]
 }
 
 // ForAWS returns the AWS-specific resource configuration
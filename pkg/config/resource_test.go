@@ -137,3 +137,218 @@ func TestGetFieldConfig(t *testing.T) {
 		}
 	}
 }
+
+func TestGetFieldConfig_NestedRenames(t *testing.T) {
+	assert := assert.New(t)
+	cfg := config.New(
+		config.WithYAML(`
+resources:
+  Repository:
+    fields:
+      Name:
+        renames:
+          - RepositoryName
+      Encryption:
+        renames:
+          - EncryptionConfiguration
+      Encryption.KMSKeyID:
+        renames:
+          - KmsKey
+        is_secret: true
+      ImageScanningConfiguration.ScanOnPushEnabled:
+        renames:
+          - ImageScanningConfiguration.ScanOnPush
+      EncryptionConfiguration.EncryptionType:
+        is_immutable: true
+`,
+		),
+	).GetResourceConfig("Repository")
+	secret := true
+	immutable := true
+	tests := []struct {
+		name      string
+		fieldPath string
+		exp       *config.FieldConfig
+		expRepath string
+	}{
+		{
+			"unrenamed path returns nil",
+			"ImageTagMutability",
+			nil,
+			"",
+		},
+		{
+			"top-level rename is not applied to a nested part",
+			"Tags.RepositoryName",
+			nil,
+			"",
+		},
+		{
+			"top-level rename preserves nested parts",
+			"RepositoryName.Suffix",
+			nil,
+			"Name.Suffix",
+		},
+		{
+			"containing field rename and nested rename",
+			"EncryptionConfiguration.KmsKey",
+			&config.FieldConfig{
+				Renames:  []string{"KmsKey"},
+				IsSecret: &secret,
+			},
+			"Encryption.KMSKeyID",
+		},
+		{
+			"config keyed by original path under a renamed containing field",
+			"EncryptionConfiguration.EncryptionType",
+			&config.FieldConfig{
+				IsImmutable: &immutable,
+			},
+			"Encryption.EncryptionType",
+		},
+		{
+			"config keyed by original path matches renamed path",
+			"Encryption.EncryptionType",
+			&config.FieldConfig{
+				IsImmutable: &immutable,
+			},
+			"",
+		},
+		{
+			"containing field rename only",
+			"EncryptionConfiguration.Other",
+			nil,
+			"Encryption.Other",
+		},
+		{
+			"nested rename with the original containing field path",
+			"ImageScanningConfiguration.scanonpush",
+			&config.FieldConfig{
+				Renames: []string{"ImageScanningConfiguration.ScanOnPush"},
+			},
+			"ImageScanningConfiguration.ScanOnPushEnabled",
+		},
+		{
+			"nested rename under a different containing field is not applied",
+			"Other.KmsKey",
+			nil,
+			"",
+		},
+	}
+	for _, test := range tests {
+		path := fieldpath.FromString(test.fieldPath)
+		fc, repath := cfg.GetFieldConfig(path)
+		assert.Equal(test.exp, fc, test.name)
+		if test.expRepath != "" {
+			assert.NotNil(repath, test.name)
+			assert.Equal(test.expRepath, repath.String(), test.name)
+		} else {
+			assert.Nil(repath, test.name)
+		}
+	}
+}
+
+func TestConflictingRenamesPanic(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{
+			"two fields rename the same top-level field",
+			`
+resources:
+  Bucket:
+    fields:
+      Name:
+        renames:
+          - Bucket
+      BucketName:
+        renames:
+          - bucket
+`,
+		},
+		{
+			"two fields rename the same nested field",
+			`
+resources:
+  Repository:
+    fields:
+      EncryptionConfiguration.KMSKeyID:
+        renames:
+          - KmsKey
+      EncryptionConfiguration.KeyID:
+        renames:
+          - EncryptionConfiguration.KmsKey
+`,
+		},
+		{
+			"two fields rename the same nested field using the original and " +
+				"renamed containing field",
+			`
+resources:
+  Repository:
+    fields:
+      Encryption:
+        renames:
+          - EncryptionConfiguration
+      Encryption.KMSKeyID:
+        renames:
+          - KmsKey
+      EncryptionConfiguration.KeyID:
+        renames:
+          - KmsKey
+`,
+		},
+		{
+			"two fields configure the same renamed field",
+			`
+resources:
+  Repository:
+    fields:
+      Encryption:
+        renames:
+          - EncryptionConfiguration
+      Encryption.EncryptionType:
+        is_immutable: true
+      EncryptionConfiguration.EncryptionType:
+        is_secret: true
+`,
+		},
+		{
+			"rename with a different containing field",
+			`
+resources:
+  Repository:
+    fields:
+      EncryptionConfiguration.KMSKeyID:
+        renames:
+          - Other.KmsKey
+`,
+		},
+	}
+	for _, test := range tests {
+		assert.Panics(
+			func() {
+				config.New(config.WithYAML(test.yaml))
+			},
+			test.name,
+		)
+	}
+
+	// A nested rename may use the original name of its renamed containing
+	// field
+	assert.NotPanics(func() {
+		config.New(config.WithYAML(`
+resources:
+  Repository:
+    fields:
+      Encryption:
+        renames:
+          - EncryptionConfiguration
+      Encryption.KMSKeyID:
+        renames:
+          - EncryptionConfiguration.KmsKey
+`))
+	})
+}
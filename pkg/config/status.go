@@ -0,0 +1,90 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package config
+
+import (
+	"fmt"
+)
+
+// StatusConfig instructs the code generator how to determine whether a
+// resource is ready, has failed or may no longer be modified from the value
+// of one of the resource's fields.
+//
+// The status field is usually only returned by the resource's Create or Get
+// operation. When the status field's top-level containing field is not a
+// member of the Create operation's input, it is discovered, as a read-only
+// field, from the output of the Create or Get operation.
+//
+// For example, a DynamoDB Table is ready when its TableStatus field is
+// ACTIVE and may not be modified while it is being deleted or archived:
+//
+// ```yaml
+// resources:
+//   Table:
+//     status:
+//       path: TableStatus
+//       ready_values:
+//         - ACTIVE
+//       failed_values:
+//         - INACCESSIBLE_ENCRYPTION_CREDENTIALS
+//       terminal_values:
+//         - DELETING
+//         - ARCHIVING
+//         - ARCHIVED
+// ```
+type StatusConfig struct {
+	// Path is the field path to the string field containing the resource's
+	// status
+	Path string `json:"path"`
+	// ReadyValues contains the status values that indicate the resource is
+	// ready. If empty, the resource is always considered ready.
+	ReadyValues []string `json:"ready_values,omitempty"`
+	// FailedValues contains the status values that indicate the resource
+	// has failed and cannot reach its desired state
+	FailedValues []string `json:"failed_values,omitempty"`
+	// TerminalValues contains the status values that indicate the resource
+	// may no longer be modified
+	TerminalValues []string `json:"terminal_values,omitempty"`
+}
+
+// validate returns an error if the status field path is missing or if a
+// status value appears in more than one list of values
+func (c *StatusConfig) validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.Path == "" {
+		return fmt.Errorf("status: path is required")
+	}
+	// listOf is a map, keyed by status value, of the name of the list of
+	// values containing that status value
+	listOf := map[string]string{}
+	for _, list := range []struct {
+		name   string
+		values []string
+	}{
+		{"ready_values", c.ReadyValues},
+		{"failed_values", c.FailedValues},
+		{"terminal_values", c.TerminalValues},
+	} {
+		for _, v := range list.values {
+			if other, found := listOf[v]; found {
+				return fmt.Errorf(
+					"status: value %s is in both %s and %s",
+					v, other, list.name,
+				)
+			}
+			listOf[v] = list.name
+		}
+	}
+	return nil
+}
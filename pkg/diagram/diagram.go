@@ -0,0 +1,276 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package diagram
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/anydotcloud/grm/pkg/path/fieldpath"
+
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+// edge describes a relationship between a resource and another Kind
+type edge struct {
+	// From is the Kind of the resource the edge starts from
+	From model.Kind
+	// FromPath is the stringified field path of the field that references
+	// To, or the empty string for parent/child edges
+	FromPath string
+	// To is the Kind that the edge points to
+	To model.Kind
+	// IsChild is true when To is a child resource of From
+	IsChild bool
+}
+
+// fieldNode describes a field and its nested fields
+type fieldNode struct {
+	Path    *fieldpath.Path
+	Field   *model.Field
+	Members []*fieldNode
+}
+
+// WriteDot writes a Graphviz DOT digraph to the supplied writer that draws
+// each of the supplied resources as a node containing a (nested) table of the
+// resource's fields. Fields that reference another resource and the
+// resource's child resources are drawn as edges.
+func WriteDot(w io.Writer, rds []*model.ResourceDefinition) error {
+	var b strings.Builder
+	b.WriteString("digraph resources {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=plaintext];\n")
+	for _, rd := range rds {
+		fmt.Fprintf(&b, "  %q [label=<\n", kindID(rd.Kind))
+		b.WriteString(
+			`<TABLE BORDER="0" CELLBORDER="1" CELLSPACING="0">` + "\n",
+		)
+		fmt.Fprintf(
+			&b, "<TR><TD BGCOLOR=\"lightgrey\"><B>%s</B></TD></TR>\n",
+			html.EscapeString(kindLabel(rd.Kind)),
+		)
+		for _, node := range getFieldTree(rd) {
+			writeDotField(&b, node)
+		}
+		b.WriteString("</TABLE>>];\n")
+	}
+	for _, kind := range getExternalKinds(rds) {
+		fmt.Fprintf(
+			&b, "  %q [shape=box, style=dashed, label=%q];\n",
+			kindID(kind), kindLabel(kind),
+		)
+	}
+	for _, e := range getEdges(rds) {
+		if e.IsChild {
+			fmt.Fprintf(
+				&b, "  %q -> %q [style=dashed, label=\"child\"];\n",
+				kindID(e.From), kindID(e.To),
+			)
+			continue
+		}
+		fmt.Fprintf(
+			&b, "  %q:%q -> %q [label=%q];\n",
+			kindID(e.From), e.FromPath, kindID(e.To), e.FromPath,
+		)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeDotField writes an HTML-like table row for the supplied field. Fields
+// with nested fields are written as a nested table.
+func writeDotField(b *strings.Builder, node *fieldNode) {
+	label := html.EscapeString(fmt.Sprintf(
+		"%s: %s", node.Path.Back(), node.Field.Definition.TypeString(),
+	))
+	if len(node.Members) == 0 {
+		fmt.Fprintf(
+			b, "<TR><TD PORT=%q ALIGN=\"LEFT\">%s</TD></TR>\n",
+			node.Path.String(), label,
+		)
+		return
+	}
+	b.WriteString("<TR><TD>")
+	b.WriteString(
+		`<TABLE BORDER="0" CELLBORDER="1" CELLSPACING="0">` + "\n",
+	)
+	fmt.Fprintf(
+		b, "<TR><TD PORT=%q ALIGN=\"LEFT\"><I>%s</I></TD></TR>\n",
+		node.Path.String(), label,
+	)
+	for _, member := range node.Members {
+		writeDotField(b, member)
+	}
+	b.WriteString("</TABLE></TD></TR>\n")
+}
+
+// WriteMermaid writes a Mermaid class diagram to the supplied writer that
+// draws each of the supplied resources as a class. Struct fields are drawn as
+// separate classes composed into their containing resource or field. Fields
+// that reference another resource and the resource's child resources are
+// drawn as relationships.
+func WriteMermaid(w io.Writer, rds []*model.ResourceDefinition) error {
+	var b strings.Builder
+	b.WriteString("classDiagram\n")
+	for _, rd := range rds {
+		id := mermaidID(kindID(rd.Kind))
+		writeMermaidClass(&b, id, kindLabel(rd.Kind), getFieldTree(rd))
+	}
+	for _, kind := range getExternalKinds(rds) {
+		fmt.Fprintf(
+			&b, "  class %s[\"%s\"]\n",
+			mermaidID(kindID(kind)), kindLabel(kind),
+		)
+	}
+	for _, e := range getEdges(rds) {
+		from := mermaidID(kindID(e.From))
+		to := mermaidID(kindID(e.To))
+		if e.IsChild {
+			fmt.Fprintf(&b, "  %s o-- %s : child\n", from, to)
+			continue
+		}
+		fmt.Fprintf(&b, "  %s --> %s : %s\n", from, to, e.FromPath)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMermaidClass writes a class with the supplied ID and label containing a
+// member for each of the supplied fields, followed by a class and composition
+// relationship for each field that has nested fields.
+func writeMermaidClass(
+	b *strings.Builder,
+	id string,
+	label string,
+	nodes []*fieldNode,
+) {
+	fmt.Fprintf(b, "  class %s[\"%s\"] {\n", id, label)
+	for _, node := range nodes {
+		fmt.Fprintf(
+			b, "    +%s %s\n",
+			node.Field.Definition.TypeString(), node.Path.Back(),
+		)
+	}
+	b.WriteString("  }\n")
+	for _, node := range nodes {
+		if len(node.Members) == 0 {
+			continue
+		}
+		memberID := id + "_" + mermaidID(node.Path.Back())
+		writeMermaidClass(b, memberID, node.Path.Back(), node.Members)
+		fmt.Fprintf(b, "  %s *-- %s : %s\n", id, memberID, node.Path.Back())
+	}
+}
+
+// getFieldTree returns the supplied resource's top-level fields, each
+// containing its nested fields, in field path order
+func getFieldTree(rd *model.ResourceDefinition) []*fieldNode {
+	res := []*fieldNode{}
+	// byPath is a map, keyed by stringified field path, of the field nodes
+	// added so far. Because field paths are sorted, a containing field is
+	// always added before its nested fields.
+	byPath := map[string]*fieldNode{}
+	for _, path := range rd.GetFieldPaths() {
+		node := &fieldNode{
+			Path:  path,
+			Field: rd.GetField(path),
+		}
+		byPath[path.String()] = node
+		if path.Size() == 1 {
+			res = append(res, node)
+			continue
+		}
+		parentPath := path.CopyAt(path.Size() - 2)
+		if parent, found := byPath[parentPath.String()]; found {
+			parent.Members = append(parent.Members, node)
+		}
+	}
+	return res
+}
+
+// getEdges returns the edges for the field references and child resources of
+// the supplied resources. Field references are inferred during discovery from
+// field names, e.g. a TableName field refers to a Table resource in the same
+// service, and child resources come from the add/remove child API operations
+// configured for a resource.
+func getEdges(rds []*model.ResourceDefinition) []edge {
+	res := []edge{}
+	for _, rd := range rds {
+		for _, path := range rd.GetFieldPaths() {
+			f := rd.GetField(path)
+			if f.Definition.References == nil {
+				continue
+			}
+			res = append(res, edge{
+				From:     rd.Kind,
+				FromPath: path.String(),
+				To:       *f.Definition.References,
+			})
+		}
+		for _, child := range rd.Children {
+			res = append(res, edge{
+				From:    rd.Kind,
+				To:      child,
+				IsChild: true,
+			})
+		}
+	}
+	return res
+}
+
+// getExternalKinds returns the Kinds that are referenced by, or are children
+// of, the supplied resources but are not themselves one of the supplied
+// resources
+func getExternalKinds(rds []*model.ResourceDefinition) []model.Kind {
+	known := map[string]bool{}
+	for _, rd := range rds {
+		known[kindID(rd.Kind)] = true
+	}
+	res := []model.Kind{}
+	for _, e := range getEdges(rds) {
+		id := kindID(e.To)
+		if known[id] {
+			continue
+		}
+		known[id] = true
+		res = append(res, e.To)
+	}
+	return res
+}
+
+// kindID returns a unique identifier for the supplied Kind
+func kindID(kind model.Kind) string {
+	return strings.Join(
+		[]string{kind.CloudProvider, kind.Service, kind.Name}, "/",
+	)
+}
+
+// kindLabel returns the label to display for the supplied Kind
+func kindLabel(kind model.Kind) string {
+	return kind.Service + "/" + kind.Name
+}
+
+// mermaidID returns the supplied identifier with any characters that are not
+// allowed in Mermaid class identifiers replaced with underscores
+func mermaidID(id string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' ||
+			(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') ||
+			(r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, id)
+}
@@ -0,0 +1,140 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package diagram_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anydotcloud/grm-generate/pkg/config"
+	"github.com/anydotcloud/grm-generate/pkg/diagram"
+	"github.com/anydotcloud/grm-generate/pkg/discover/aws/testutil"
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+var (
+	// ecrConfig makes LifecyclePolicy a child resource of the ECR Repository
+	// resource
+	ecrConfig = config.New(
+		config.WithYAML(`
+resources:
+  Repository:
+    aws:
+      operations:
+        - type: add_child
+          id: PutLifecyclePolicy
+`,
+		),
+	)
+)
+
+// discoverResources returns the resources discovered in the DynamoDB and ECR
+// testdata API models. The DynamoDB Backup resource's TableName field refers
+// to the Table resource and the ECR Repository resource has a nested
+// EncryptionConfiguration struct field and a LifecyclePolicy child resource.
+func discoverResources(t *testing.T) []*model.ResourceDefinition {
+	return append(
+		testutil.DiscoverResources(t, config.New(), "dynamodb"),
+		testutil.DiscoverResources(t, ecrConfig, "ecr")...,
+	)
+}
+
+func TestWriteDot(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	var b strings.Builder
+	require.Nil(diagram.WriteDot(&b, discoverResources(t)))
+	got := b.String()
+
+	assert.True(strings.HasPrefix(got, "digraph resources {\n"))
+	assert.Contains(got, `"aws/ecr/Repository" [label=<`)
+	assert.Contains(got, "<B>ecr/Repository</B>")
+	assert.Contains(
+		got,
+		`<TR><TD PORT="RepositoryName" ALIGN="LEFT">RepositoryName: string</TD></TR>`,
+	)
+	// The nested fields are inside the containing field's nested table
+	assert.Contains(
+		got,
+		`<TR><TD PORT="EncryptionConfiguration" ALIGN="LEFT">`+
+			`<I>EncryptionConfiguration: struct</I></TD></TR>`+"\n"+
+			`<TR><TD PORT="EncryptionConfiguration.EncryptionType" ALIGN="LEFT">`+
+			`EncryptionType: string</TD></TR>`+"\n"+
+			`<TR><TD PORT="EncryptionConfiguration.KMSKey" ALIGN="LEFT">`+
+			`KMSKey: string</TD></TR>`+"\n"+
+			`</TABLE></TD></TR>`,
+	)
+	// The discovered reference is drawn from the referring field
+	assert.Contains(
+		got,
+		`"aws/dynamodb/Backup":"TableName" -> `+
+			`"aws/dynamodb/Table" [label="TableName"];`,
+	)
+	assert.NotContains(got, `"aws/dynamodb/Table" [shape=box`)
+	// The child Kind is not one of the resources
+	assert.Contains(
+		got,
+		`"aws/ecr/LifecyclePolicy" [shape=box, style=dashed, `+
+			`label="ecr/LifecyclePolicy"];`,
+	)
+	assert.Contains(
+		got,
+		`"aws/ecr/Repository" -> "aws/ecr/LifecyclePolicy" `+
+			`[style=dashed, label="child"];`,
+	)
+}
+
+func TestWriteMermaid(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	var b strings.Builder
+	require.Nil(diagram.WriteMermaid(&b, discoverResources(t)))
+	got := b.String()
+
+	assert.True(strings.HasPrefix(got, "classDiagram\n"))
+	assert.Contains(
+		got,
+		"  class aws_ecr_Repository[\"ecr/Repository\"] {\n"+
+			"    +struct EncryptionConfiguration\n"+
+			"    +struct ImageScanningConfiguration\n"+
+			"    +string ImageTagMutability\n"+
+			"    +string RegistryID\n"+
+			"    +string RepositoryName\n"+
+			"    +[]struct Tags\n"+
+			"  }\n",
+	)
+	assert.Contains(
+		got,
+		"  class aws_ecr_Repository_EncryptionConfiguration"+
+			"[\"EncryptionConfiguration\"] {\n"+
+			"    +string EncryptionType\n"+
+			"    +string KMSKey\n"+
+			"  }\n",
+	)
+	assert.Contains(
+		got,
+		"  aws_ecr_Repository *-- aws_ecr_Repository_EncryptionConfiguration"+
+			" : EncryptionConfiguration\n",
+	)
+	assert.Contains(
+		got, "  aws_dynamodb_Backup --> aws_dynamodb_Table : TableName\n",
+	)
+	assert.Contains(got, "  class aws_ecr_LifecyclePolicy[\"ecr/LifecyclePolicy\"]\n")
+	assert.Contains(
+		got, "  aws_ecr_Repository o-- aws_ecr_LifecyclePolicy : child\n",
+	)
+}
@@ -0,0 +1,199 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package diff
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/samber/lo"
+
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+// ChangeType describes the kind of difference between two discovery runs
+type ChangeType string
+
+const (
+	// ChangeTypeResourceAdded indicates a resource that only the new run has
+	ChangeTypeResourceAdded ChangeType = "resource_added"
+	// ChangeTypeResourceRemoved indicates a resource that only the old run has
+	ChangeTypeResourceRemoved ChangeType = "resource_removed"
+	// ChangeTypeFieldAdded indicates a field that only the new resource has
+	ChangeTypeFieldAdded ChangeType = "field_added"
+	// ChangeTypeFieldRemoved indicates a field that only the old resource has
+	ChangeTypeFieldRemoved ChangeType = "field_removed"
+	// ChangeTypeTypeChanged indicates a change to a field's type, element
+	// type, key type or value type
+	ChangeTypeTypeChanged ChangeType = "type_changed"
+	// ChangeTypeRequiredChanged indicates a field that became required or
+	// optional
+	ChangeTypeRequiredChanged ChangeType = "required_changed"
+	// ChangeTypeImmutableChanged indicates a field that became immutable or
+	// mutable
+	ChangeTypeImmutableChanged ChangeType = "immutable_changed"
+	// ChangeTypeReadOnlyChanged indicates a field that became read-only or
+	// writable
+	ChangeTypeReadOnlyChanged ChangeType = "read_only_changed"
+)
+
+// Change describes a single difference in a resource or field between two
+// discovery runs
+type Change struct {
+	// Kind is the type of the resource that changed
+	Kind model.Kind `json:"kind"`
+	// Path is the stringified field path of the field that changed, or the
+	// empty string if the change is to the resource itself
+	Path string `json:"path,omitempty"`
+	// Type describes what changed
+	Type ChangeType `json:"type"`
+	// Old contains a description of the old value, if any
+	Old string `json:"old,omitempty"`
+	// New contains a description of the new value, if any
+	New string `json:"new,omitempty"`
+	// IsBreaking is true if code or configuration that worked with the old
+	// resource may not work with the new resource
+	IsBreaking bool `json:"is_breaking"`
+}
+
+// Compare returns the Changes between an old and new set of
+// ResourceDefinitions. The returned Changes are sorted by Kind and then field
+// path.
+//
+// Removing a resource or field, changing a field's type, making a field
+// required, immutable or read-only and adding a required field are breaking
+// changes. All other changes are non-breaking.
+func Compare(
+	oldRDs []*model.ResourceDefinition,
+	newRDs []*model.ResourceDefinition,
+) []*Change {
+	res := []*Change{}
+	oldByKind := byKind(oldRDs)
+	newByKind := byKind(newRDs)
+	for kind, oldRD := range oldByKind {
+		newRD, found := newByKind[kind]
+		if !found {
+			res = append(res, &Change{
+				Kind:       oldRD.Kind,
+				Type:       ChangeTypeResourceRemoved,
+				IsBreaking: true,
+			})
+			continue
+		}
+		res = append(res, compareFields(oldRD, newRD)...)
+	}
+	for kind, newRD := range newByKind {
+		if _, found := oldByKind[kind]; !found {
+			res = append(res, &Change{
+				Kind: newRD.Kind,
+				Type: ChangeTypeResourceAdded,
+			})
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Kind != res[j].Kind {
+			return res[i].Kind.Less(res[j].Kind)
+		}
+		if res[i].Path != res[j].Path {
+			return res[i].Path < res[j].Path
+		}
+		return res[i].Type < res[j].Type
+	})
+	return res
+}
+
+// HasBreakingChanges returns true if any of the supplied Changes is breaking
+func HasBreakingChanges(changes []*Change) bool {
+	return lo.ContainsBy(changes, func(c *Change) bool {
+		return c.IsBreaking
+	})
+}
+
+// compareFields returns the Changes between the fields of an old and new
+// version of a resource
+func compareFields(
+	oldRD *model.ResourceDefinition,
+	newRD *model.ResourceDefinition,
+) []*Change {
+	res := []*Change{}
+	kind := newRD.Kind
+	for _, path := range oldRD.GetFieldPaths() {
+		pathStr := path.String()
+		oldDef := oldRD.GetField(path).Definition
+		newField := newRD.GetField(path)
+		if newField == nil {
+			res = append(res, &Change{
+				Kind:       kind,
+				Path:       pathStr,
+				Type:       ChangeTypeFieldRemoved,
+				Old:        oldDef.TypeString(),
+				IsBreaking: true,
+			})
+			continue
+		}
+		newDef := newField.Definition
+		if oldDef.TypeString() != newDef.TypeString() {
+			res = append(res, &Change{
+				Kind:       kind,
+				Path:       pathStr,
+				Type:       ChangeTypeTypeChanged,
+				Old:        oldDef.TypeString(),
+				New:        newDef.TypeString(),
+				IsBreaking: true,
+			})
+		}
+		for changeType, vals := range map[ChangeType][2]bool{
+			ChangeTypeRequiredChanged:  {oldDef.IsRequired, newDef.IsRequired},
+			ChangeTypeImmutableChanged: {oldDef.IsImmutable, newDef.IsImmutable},
+			ChangeTypeReadOnlyChanged:  {oldDef.IsReadOnly, newDef.IsReadOnly},
+		} {
+			if vals[0] == vals[1] {
+				continue
+			}
+			// Adding a constraint to a field is breaking. Removing one is
+			// not.
+			res = append(res, &Change{
+				Kind:       kind,
+				Path:       pathStr,
+				Type:       changeType,
+				Old:        strconv.FormatBool(vals[0]),
+				New:        strconv.FormatBool(vals[1]),
+				IsBreaking: vals[1],
+			})
+		}
+	}
+	for _, path := range newRD.GetFieldPaths() {
+		if oldRD.GetField(path) != nil {
+			continue
+		}
+		newDef := newRD.GetField(path).Definition
+		res = append(res, &Change{
+			Kind:       kind,
+			Path:       path.String(),
+			Type:       ChangeTypeFieldAdded,
+			New:        newDef.TypeString(),
+			IsBreaking: newDef.IsRequired,
+		})
+	}
+	return res
+}
+
+// byKind returns a map, keyed by Kind, of the supplied ResourceDefinitions
+func byKind(
+	rds []*model.ResourceDefinition,
+) map[model.Kind]*model.ResourceDefinition {
+	res := make(map[model.Kind]*model.ResourceDefinition, len(rds))
+	for _, rd := range rds {
+		res[rd.Kind] = rd
+	}
+	return res
+}
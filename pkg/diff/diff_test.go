@@ -0,0 +1,195 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/anydotcloud/grm/pkg/path/fieldpath"
+	"github.com/anydotcloud/grm/pkg/types/resource/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anydotcloud/grm-generate/pkg/config"
+	"github.com/anydotcloud/grm-generate/pkg/diff"
+	"github.com/anydotcloud/grm-generate/pkg/discover/aws/testutil"
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+// discoverECR returns the resources discovered in the ECR testdata API model,
+// the PullThroughCacheRule and Repository resources
+func discoverECR(t *testing.T) []*model.ResourceDefinition {
+	rds := testutil.DiscoverResources(t, config.New(), "ecr")
+	require.Len(t, rds, 2)
+	return rds
+}
+
+// newRepository returns the ECR Repository resource discovered in the ECR
+// testdata API model
+func newRepository(t *testing.T) *model.ResourceDefinition {
+	return discoverECR(t)[1]
+}
+
+func TestCompare_NoChanges(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	oldRDs := discoverECR(t)
+	// Round-trip through the YAML written by `discover -o yaml` to ensure
+	// that saved discovery output compares equal to discovered resources
+	y, err := model.MarshalYAML(oldRDs)
+	require.Nil(err)
+	newRDs, err := model.Unmarshal(y)
+	require.Nil(err)
+	require.Len(newRDs, 2)
+
+	changes := diff.Compare(oldRDs, newRDs)
+	assert.Empty(changes)
+	assert.False(diff.HasBreakingChanges(changes))
+}
+
+func TestCompare(t *testing.T) {
+	assert := assert.New(t)
+	repo := model.NewKind("aws", "ecr", "Repository")
+	rule := model.NewKind("aws", "ecr", "PullThroughCacheRule")
+	tests := []struct {
+		name   string
+		modify func(rd *model.ResourceDefinition)
+		exp    []*diff.Change
+	}{
+		{
+			"removed field is breaking",
+			func(rd *model.ResourceDefinition) {
+				delete(rd.Fields, "ImageTagMutability")
+			},
+			[]*diff.Change{
+				{
+					Kind:       repo,
+					Path:       "ImageTagMutability",
+					Type:       diff.ChangeTypeFieldRemoved,
+					Old:        "string",
+					IsBreaking: true,
+				},
+			},
+		},
+		{
+			"added optional field is not breaking",
+			func(rd *model.ResourceDefinition) {
+				rd.AddField(model.NewField(
+					fieldpath.FromString("ScanOnPush"), nil,
+					&model.FieldDefinition{Type: schema.FieldTypeBool},
+				))
+			},
+			[]*diff.Change{
+				{
+					Kind: repo,
+					Path: "ScanOnPush",
+					Type: diff.ChangeTypeFieldAdded,
+					New:  "bool",
+				},
+			},
+		},
+		{
+			"added required field is breaking",
+			func(rd *model.ResourceDefinition) {
+				rd.AddField(model.NewField(
+					fieldpath.FromString("ScanOnPush"), nil,
+					&model.FieldDefinition{
+						Type:       schema.FieldTypeBool,
+						IsRequired: true,
+					},
+				))
+			},
+			[]*diff.Change{
+				{
+					Kind:       repo,
+					Path:       "ScanOnPush",
+					Type:       diff.ChangeTypeFieldAdded,
+					New:        "bool",
+					IsBreaking: true,
+				},
+			},
+		},
+		{
+			"changed list element type is breaking",
+			func(rd *model.ResourceDefinition) {
+				rd.Fields["Tags"].Definition.ElementType = schema.FieldTypeString
+			},
+			[]*diff.Change{
+				{
+					Kind:       repo,
+					Path:       "Tags",
+					Type:       diff.ChangeTypeTypeChanged,
+					Old:        "[]struct",
+					New:        "[]string",
+					IsBreaking: true,
+				},
+			},
+		},
+		{
+			"field becoming optional is not breaking but becoming immutable is",
+			func(rd *model.ResourceDefinition) {
+				def := rd.Fields["RepositoryName"].Definition
+				def.IsRequired = false
+				def.IsImmutable = true
+			},
+			[]*diff.Change{
+				{
+					Kind:       repo,
+					Path:       "RepositoryName",
+					Type:       diff.ChangeTypeImmutableChanged,
+					Old:        "false",
+					New:        "true",
+					IsBreaking: true,
+				},
+				{
+					Kind: repo,
+					Path: "RepositoryName",
+					Type: diff.ChangeTypeRequiredChanged,
+					Old:  "true",
+					New:  "false",
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		newRD := newRepository(t)
+		test.modify(newRD)
+		got := diff.Compare(
+			[]*model.ResourceDefinition{newRepository(t)},
+			[]*model.ResourceDefinition{newRD},
+		)
+		assert.Equal(test.exp, got, test.name)
+	}
+
+	// Adding a resource is not breaking but removing one is
+	rds := discoverECR(t)
+	got := diff.Compare(
+		[]*model.ResourceDefinition{rds[1]},
+		[]*model.ResourceDefinition{rds[0]},
+	)
+	assert.Equal(
+		[]*diff.Change{
+			{
+				Kind: rule,
+				Type: diff.ChangeTypeResourceAdded,
+			},
+			{
+				Kind:       repo,
+				Type:       diff.ChangeTypeResourceRemoved,
+				IsBreaking: true,
+			},
+		},
+		got,
+	)
+	assert.True(diff.HasBreakingChanges(got))
+}
@@ -20,6 +20,7 @@ import (
 	"sort"
 
 	awssdkmodel "github.com/aws/aws-sdk-go/private/model/api"
+	"github.com/samber/lo"
 
 	"github.com/anydotcloud/grm-generate/pkg/discover"
 	"github.com/anydotcloud/grm-generate/pkg/git"
@@ -29,7 +30,7 @@ import (
 
 // discoverer is a helper struct that helps work with the aws-sdk-go models and
 // API model loader. It implements the `pkg/discover.DiscoversResources`
-// interface.
+// interface and the `pkg/discover.ReportsCoverage` interface.
 type discoverer struct {
 	opts option
 	repo *git.Repository
@@ -41,13 +42,56 @@ type discoverer struct {
 func (d *discoverer) DiscoverResources(
 	ctx context.Context,
 ) ([]*model.ResourceDefinition, error) {
+	if err := d.loadAPIs(ctx); err != nil {
+		return nil, err
+	}
+	res := []*model.ResourceDefinition{}
+	services := lo.Keys(d.apis)
+	sort.Strings(services)
+	for _, service := range services {
+		serviceResources, err := GetResourceDefinitionsForService(
+			ctx, service, d.apis[service], d.opts.cfg,
+		)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, serviceResources...)
+	}
+	model.SortResourceDefinitions(res)
+	return res, nil
+}
+
+func (d *discoverer) ReportCoverage(
+	ctx context.Context,
+) ([]*discover.ServiceCoverage, error) {
+	if err := d.loadAPIs(ctx); err != nil {
+		return nil, err
+	}
+	services := lo.Keys(d.apis)
+	sort.Strings(services)
+	res := make([]*discover.ServiceCoverage, len(services))
+	for x, service := range services {
+		res[x] = GetCoverageForService(
+			ctx, service, d.apis[service], d.opts.cfg,
+		)
+	}
+	return res, nil
+}
+
+// loadAPIs opens the cached aws-sdk-go repository and loads the API models
+// for each service for which we are discovering resources.
+func (d *discoverer) loadAPIs(
+	ctx context.Context,
+) error {
 	var err error
 	l := log.FromContext(ctx)
-	if d.repo == nil {
+	// The aws-sdk-go repository is only needed when discovering the API model
+	// files from the cache path
+	if d.repo == nil && len(d.opts.apiModelPaths) == 0 {
 		l.Debug("loading git repository", "cache_path", d.opts.cachePath)
 		d.repo, err = git.Open(d.opts.cachePath)
 		if err != nil {
-			return nil, fmt.Errorf(
+			return fmt.Errorf(
 				"error loading repository from %s: %v",
 				d.opts.cachePath, err,
 			)
@@ -56,23 +100,10 @@ func (d *discoverer) DiscoverResources(
 	var modelPaths []string
 	modelPaths, err = d.getModelPaths(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	d.apis, err = GetAPIs(ctx, d.opts.cachePath, modelPaths)
-	if err != nil {
-		return nil, err
-	}
-	res := []*model.ResourceDefinition{}
-	for service, api := range d.apis {
-		serviceResources, err := GetResourceDefinitionsForService(
-			ctx, service, api, d.opts.cfg,
-		)
-		if err != nil {
-			return nil, err
-		}
-		res = append(res, serviceResources...)
-	}
-	return res, nil
+	return err
 }
 
 // getAPIs returns a map, keyed by service package name, of API structs for
@@ -209,3 +240,30 @@ func New(
 		apis: map[string]*awssdkmodel.API{},
 	}
 }
+
+// LoadAPIs returns a map, keyed by service package name, of API structs for
+// each service for which resources are discovered using the supplied options
+func LoadAPIs(
+	ctx context.Context,
+	opts ...option,
+) (map[string]*awssdkmodel.API, error) {
+	d := &discoverer{
+		opts: mergeOptions(opts),
+		apis: map[string]*awssdkmodel.API{},
+	}
+	if err := d.loadAPIs(ctx); err != nil {
+		return nil, err
+	}
+	return d.apis, nil
+}
+
+// NewCoverageReporter returns a new ReportsCoverage implementer for AWS
+// service APIs
+func NewCoverageReporter(
+	opts ...option,
+) discover.ReportsCoverage {
+	return &discoverer{
+		opts: mergeOptions(opts),
+		apis: map[string]*awssdkmodel.API{},
+	}
+}
@@ -0,0 +1,101 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package aws
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	awssdkmodel "github.com/aws/aws-sdk-go/private/model/api"
+	"github.com/samber/lo"
+
+	"github.com/anydotcloud/grm-generate/pkg/config"
+	"github.com/anydotcloud/grm-generate/pkg/discover"
+)
+
+// GetCoverageForService returns a `ServiceCoverage` struct that describes
+// every resource name inferred from a supplied AWS service API, the
+// operations associated with each of those resources, why a resource was
+// skipped during discovery, which operations were ignored by configuration
+// and the proportion of the API's operations that were claimed by discovered
+// resources.
+func GetCoverageForService(
+	ctx context.Context,
+	service string, // the service package name
+	api *awssdkmodel.API,
+	cfg *config.Config,
+) *discover.ServiceCoverage {
+	res := &discover.ServiceCoverage{
+		Service:           service,
+		Resources:         []*discover.ResourceCoverage{},
+		OrphanOperations:  []string{},
+		IgnoredOperations: []string{},
+		TotalOperations:   len(api.Operations),
+	}
+
+	resOpMap := GetResourceOperationMap(ctx, api, cfg)
+
+	// attached contains the API operations that were associated with any
+	// inferred resource. claimed contains the API operations that were
+	// associated with a discovered resource.
+	attached := map[*awssdkmodel.Operation]bool{}
+	claimed := map[*awssdkmodel.Operation]bool{}
+	for resName, ops := range resOpMap {
+		rc := &discover.ResourceCoverage{
+			Name:       resName,
+			Operations: map[string]string{},
+			SkipReason: getResourceSkipReason(ops),
+		}
+		for opType, op := range ops {
+			// Operations with an unknown OpType are keyed in the resource
+			// operation map by the operation ID. They are orphans, not
+			// resources.
+			if opType == OpTypeUnknown {
+				continue
+			}
+			rc.Operations[opType.String()] = op.ExportedName
+			attached[op] = true
+			if !rc.IsSkipped() {
+				claimed[op] = true
+			}
+		}
+		if len(rc.Operations) == 0 {
+			continue
+		}
+		res.Resources = append(res.Resources, rc)
+	}
+	sort.Slice(res.Resources, func(i, j int) bool {
+		return res.Resources[i].Name < res.Resources[j].Name
+	})
+
+	opIDs := lo.Keys(api.Operations)
+	sort.Strings(opIDs)
+	for _, opID := range opIDs {
+		if attached[api.Operations[opID]] {
+			continue
+		}
+		_, resName := getOpTypeAndResourceNameFromOpID(opID, cfg)
+		if isOperationIgnored(opID, resName, cfg) {
+			res.IgnoredOperations = append(res.IgnoredOperations, opID)
+		} else {
+			res.OrphanOperations = append(res.OrphanOperations, opID)
+		}
+	}
+
+	res.ClaimedOperations = len(claimed)
+	if res.TotalOperations > 0 {
+		pct := float64(res.ClaimedOperations) / float64(res.TotalOperations) * 100
+		res.ClaimedPercent = math.Round(pct*100) / 100
+	}
+	return res
+}
@@ -0,0 +1,173 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package aws_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anydotcloud/grm-generate/pkg/config"
+	"github.com/anydotcloud/grm-generate/pkg/discover"
+	"github.com/anydotcloud/grm-generate/pkg/discover/aws"
+)
+
+func Test_GetCoverageForService(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	ctx := context.TODO()
+	service := "ecr"
+	api := apis[service]
+	require.NotNil(api, "expected non-nil API for ECR service")
+
+	c := aws.GetCoverageForService(ctx, service, api, nil)
+	require.NotNil(c)
+	assert.Equal("ecr", c.Service)
+	assert.Equal(41, c.TotalOperations)
+	// CreateRepository, DeleteRepository, DescribeRepositories,
+	// CreatePullThroughCacheRule, DeletePullThroughCacheRule,
+	// DescribePullThroughCacheRules
+	assert.Equal(6, c.ClaimedOperations)
+	assert.Equal(14.63, c.ClaimedPercent)
+
+	names := []string{}
+	byName := map[string]*discover.ResourceCoverage{}
+	for _, rc := range c.Resources {
+		names = append(names, rc.Name)
+		byName[rc.Name] = rc
+	}
+	assert.True(sort.StringsAreSorted(names))
+
+	repo, found := byName["Repository"]
+	require.True(found)
+	assert.False(repo.IsSkipped())
+	assert.Equal(
+		map[string]string{
+			"create": "CreateRepository",
+			"delete": "DeleteRepository",
+			"list":   "DescribeRepositories",
+		},
+		repo.Operations,
+	)
+
+	policy, found := byName["LifecyclePolicy"]
+	require.True(found)
+	assert.True(policy.IsSkipped())
+	assert.Equal("no create operation", policy.SkipReason)
+
+	assert.True(sort.StringsAreSorted(c.OrphanOperations))
+	assert.Contains(c.OrphanOperations, "TagResource")
+	assert.Contains(c.OrphanOperations, "PutImage")
+	assert.NotContains(c.OrphanOperations, "CreateRepository")
+}
+
+func Test_GetCoverageForService_IgnoreConfig(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	ctx := context.TODO()
+	service := "ecr"
+	api := apis[service]
+	require.NotNil(api, "expected non-nil API for ECR service")
+
+	cfg := config.New(
+		config.WithYAML(`
+ignore:
+  resources:
+    - PullThroughCacheRule
+  operations:
+    - TagResource
+`,
+		),
+	)
+
+	c := aws.GetCoverageForService(ctx, service, api, cfg)
+	require.NotNil(c)
+	assert.Equal(41, c.TotalOperations)
+	assert.Equal(3, c.ClaimedOperations)
+	assert.Equal(
+		[]string{
+			"CreatePullThroughCacheRule",
+			"DeletePullThroughCacheRule",
+			"DescribePullThroughCacheRules",
+			"TagResource",
+		},
+		c.IgnoredOperations,
+	)
+	assert.NotContains(c.OrphanOperations, "TagResource")
+	for _, rc := range c.Resources {
+		assert.NotEqual("PullThroughCacheRule", rc.Name)
+	}
+}
+
+func Test_GetCoverageForService_ResourceRenames(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	ctx := context.TODO()
+	service := "ecr"
+	api := apis[service]
+	require.NotNil(api, "expected non-nil API for ECR service")
+
+	cfg := config.New(
+		config.WithYAML(`
+resources:
+  Repo:
+    renames:
+      - Repository
+  Policy:
+    renames:
+      - LifecyclePolicy
+      - RepositoryPolicy
+`,
+		),
+	)
+
+	c := aws.GetCoverageForService(ctx, service, api, cfg)
+	require.NotNil(c)
+
+	byName := map[string]*discover.ResourceCoverage{}
+	for _, rc := range c.Resources {
+		byName[rc.Name] = rc
+	}
+	for _, name := range []string{
+		"Repository", "LifecyclePolicy", "RepositoryPolicy",
+	} {
+		_, found := byName[name]
+		assert.False(found, name)
+	}
+
+	repo, found := byName["Repo"]
+	require.True(found)
+	assert.Equal(
+		map[string]string{
+			"create": "CreateRepository",
+			"delete": "DeleteRepository",
+			"list":   "DescribeRepositories",
+		},
+		repo.Operations,
+	)
+
+	policy, found := byName["Policy"]
+	require.True(found)
+	// Both LifecyclePolicy and RepositoryPolicy have delete and get
+	// operations. Operations are processed in sorted order, so the
+	// RepositoryPolicy operations win.
+	assert.Equal(
+		map[string]string{
+			"delete": "DeleteRepositoryPolicy",
+			"get":    "GetRepositoryPolicy",
+		},
+		policy.Operations,
+	)
+}
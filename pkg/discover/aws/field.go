@@ -29,7 +29,9 @@ import (
 // VisitMemberShape collects information on the possible field's definition by
 // examining both the FieldConfig and the AWS SDK model ShapeRef and adds a new
 // Field to the supplied ResourceDefinition as appropriate, returning the
-// discovered FieldDefinition representing the member shapeRef.
+// discovered FieldDefinition representing the member shapeRef. If the field
+// path is ignored by the ResourceDefinition's IgnoreConfig, no Field is added
+// and nil is returned.
 //
 // This function is called recursively for nested fields.
 func VisitMemberShape(
@@ -44,6 +46,10 @@ func VisitMemberShape(
 	containerShape *awssdkmodel.Shape, // the "parent" or "containing" shape
 	shapeRef *awssdkmodel.ShapeRef,
 ) *model.FieldDefinition {
+	ic := rd.Ignore
+	if ic.IsFieldPathIgnored(rd.Kind.Name, path) {
+		return nil
+	}
 	def := &model.FieldDefinition{
 		Type:        schema.FieldTypeUnknown,
 		ValueType:   schema.FieldTypeUnknown,
@@ -64,6 +70,9 @@ func VisitMemberShape(
 	if fc != nil && fc.IsSecret != nil {
 		def.IsSecret = *fc.IsSecret
 	}
+	if fc != nil && fc.IsSet != nil {
+		def.IsSet = *fc.IsSet
+	}
 	if fc != nil && fc.Type != nil {
 		def.Type = schema.StringToFieldType(*fc.Type)
 	}
@@ -77,9 +86,14 @@ func VisitMemberShape(
 		def.ValueType = schema.StringToFieldType(*fc.ValueType)
 	}
 
+	def.Documentation = shapeRefDocumentation(shapeRef)
+
 	if repath != nil {
 		// The original field name was renamed...
 		path = repath
+		if ic.IsFieldPathIgnored(rd.Kind.Name, path) {
+			return nil
+		}
 	}
 	if def.Type == schema.FieldTypeUnknown {
 		if shapeRef == nil {
@@ -104,6 +118,7 @@ func VisitMemberShape(
 			panic(msg)
 		}
 		def.Type = fieldTypeFromShape(shape)
+		def.EnumValues = shapeEnumValues(shape)
 		switch shape.Type {
 		case "list", "map":
 			if shape.Type == "list" {
@@ -161,6 +176,22 @@ func VisitMemberShape(
 	return def
 }
 
+// shapeEnumValues returns a copy of the enum values of the supplied shape or,
+// if the supplied shape is a list or map, of the list member or map value
+// shape. Returns nil if the values are not restricted to a fixed set.
+func shapeEnumValues(shape *awssdkmodel.Shape) []string {
+	switch shape.Type {
+	case "list":
+		shape = shape.MemberRef.Shape
+	case "map":
+		shape = shape.ValueRef.Shape
+	}
+	if shape == nil || len(shape.Enum) == 0 {
+		return nil
+	}
+	return append([]string{}, shape.Enum...)
+}
+
 // fieldIsRequired determines whether the supplied field is required. The
 // supplied field config, if not nil, is used as an override. Otherwise, we
 // look in the supplied container shape's Required attribute for a
@@ -183,6 +214,30 @@ func fieldIsRequired(
 	)
 }
 
+// shapeRefDocumentation returns the plain-text documentation for the supplied
+// ShapeRef, or the empty string if there is no documentation. The aws-sdk-go
+// model loader converts API documentation into Go comments, so we strip the
+// comment markers here.
+func shapeRefDocumentation(
+	shapeRef *awssdkmodel.ShapeRef,
+) string {
+	if shapeRef == nil {
+		return ""
+	}
+	doc := shapeRef.Documentation
+	if doc == "" && shapeRef.Shape != nil {
+		doc = shapeRef.Shape.Documentation
+	}
+	lines := []string{}
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(
+			strings.TrimSpace(line), "//",
+		))
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
 // fieldTypeFromShape returns the schema.FieldType from an aws-sdk-go
 // Shape.Type string.
 func fieldTypeFromShape(
@@ -223,8 +278,18 @@ func getMemberFieldDefinitions(
 		memberPath := containerPath.Copy()
 		memberPath.PushBack(cleanMemberNames.Camel)
 		memberShape := containerShape.MemberRefs[memberName]
+		// The member field may be renamed, in which case the member field
+		// definition is keyed by the new name
+		defName := cleanMemberNames.Camel
+		if _, repath := cfg.GetFieldConfig(memberPath); repath != nil {
+			defName = names.New(repath.Back()).Camel
+		}
 		memberDef := VisitMemberShape(ctx, rd, memberPath, cfg, containerShape, memberShape)
-		defs[cleanMemberNames.Camel] = memberDef
+		if memberDef == nil {
+			// the member field is ignored...
+			continue
+		}
+		defs[defName] = memberDef
 	}
 	return defs
 }
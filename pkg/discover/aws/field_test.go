@@ -128,6 +128,29 @@ func Test_VisitMemberShape(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"shape documentation is converted to plain text",
+			"Name",
+			nil,
+			nil,
+			&awssdkmodel.ShapeRef{
+				ShapeName: "BucketName",
+				Documentation: `// The name of the bucket.
+//
+// Must be globally unique.`,
+				Shape: &awssdkmodel.Shape{
+					Type: "string",
+				},
+			},
+			&model.FieldDefinition{
+				Type:          schema.FieldTypeString,
+				ValueType:     schema.FieldTypeUnknown,
+				KeyType:       schema.FieldTypeUnknown,
+				ElementType:   schema.FieldTypeUnknown,
+				Documentation: "The name of the bucket.\n\nMust be globally unique.",
+			},
+			false,
+		},
 	}
 	ctx := context.TODO()
 	for _, test := range tests {
@@ -151,3 +174,33 @@ func Test_VisitMemberShape(t *testing.T) {
 		}
 	}
 }
+
+func Test_VisitMemberShapeIgnored(t *testing.T) {
+	assert := assert.New(t)
+	cfg := config.New(config.WithYAML(`
+ignore:
+  field_paths:
+    - Bucket.Name
+`))
+	kind := model.NewKind("aws", "s3", "Bucket")
+	shapeRef := &awssdkmodel.ShapeRef{
+		ShapeName: "BucketName",
+		Shape:     &awssdkmodel.Shape{Type: "string"},
+	}
+
+	rd := model.NewResourceDefinition(nil, kind)
+	rd.Ignore = cfg.GetIgnoreConfig()
+	got := aws.VisitMemberShape(
+		context.TODO(), rd, fieldpath.FromString("Name"), nil, nil, shapeRef,
+	)
+	assert.Nil(got)
+	assert.Nil(rd.GetField(fieldpath.FromString("Name")))
+
+	// Without an IgnoreConfig, no field path is ignored
+	rd.Ignore = nil
+	got = aws.VisitMemberShape(
+		context.TODO(), rd, fieldpath.FromString("Name"), nil, nil, shapeRef,
+	)
+	assert.NotNil(got)
+	assert.NotNil(rd.GetField(fieldpath.FromString("Name")))
+}
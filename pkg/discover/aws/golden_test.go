@@ -0,0 +1,73 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package aws_test
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anydotcloud/grm-generate/pkg/discover/aws"
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+var updateGolden = flag.Bool(
+	"update", false, "update the golden files in testdata/",
+)
+
+// discoverYAML discovers resources in the supplied API model files and
+// returns the YAML-serialized ResourceDefinitions
+func discoverYAML(t *testing.T, modelPaths ...string) []byte {
+	require := require.New(t)
+	disco := aws.New(aws.WithAPIModelPaths(modelPaths...))
+	rds, err := disco.DiscoverResources(context.TODO())
+	require.Nil(err)
+	y, err := model.MarshalYAML(rds)
+	require.Nil(err)
+	return y
+}
+
+func Test_DiscoverResources_Golden(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	goldenPath := filepath.Join("testdata", "discover-ecr-dynamodb.golden.yaml")
+	modelPaths := []string{
+		filepath.Join(apiModelDir, "ecr-api.json"),
+		filepath.Join(apiModelDir, "dynamodb-api.json"),
+	}
+
+	got := discoverYAML(t, modelPaths...)
+	if *updateGolden {
+		require.Nil(ioutil.WriteFile(goldenPath, got, 0644))
+	}
+	exp, err := ioutil.ReadFile(goldenPath)
+	require.Nil(err)
+	assert.Equal(string(exp), string(got))
+
+	// The golden file can be loaded back into the model without any loss
+	rds, err := model.Unmarshal(exp)
+	require.Nil(err)
+	again, err := model.MarshalYAML(rds)
+	require.Nil(err)
+	assert.Equal(string(exp), string(again))
+
+	// Discovery iterates over maps of APIs, operations and shapes. Make sure
+	// that the output is byte-identical from run to run.
+	for x := 0; x < 5; x++ {
+		assert.Equal(string(got), string(discoverYAML(t, modelPaths...)))
+	}
+}
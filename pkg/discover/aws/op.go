@@ -14,10 +14,12 @@ package aws
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	awssdkmodel "github.com/aws/aws-sdk-go/private/model/api"
 	"github.com/gertd/go-pluralize"
+	"github.com/samber/lo"
 
 	"github.com/anydotcloud/grm-generate/pkg/config"
 )
@@ -41,11 +43,48 @@ const (
 	OpTypeSetAttributes
 )
 
-type resourceOperationMap map[string]map[OpType]*awssdkmodel.Operation
+// String returns the stringified OpType. The returned string can be
+// translated back into the OpType with getOpTypeFromString.
+func (t OpType) String() string {
+	switch t {
+	case OpTypeCreate:
+		return "create"
+	case OpTypeCreateBatch:
+		return "create_batch"
+	case OpTypeDelete:
+		return "delete"
+	case OpTypeReplace:
+		return "replace"
+	case OpTypeUpdate:
+		return "update"
+	case OpTypeAddChild:
+		return "add_child"
+	case OpTypeAddChildren:
+		return "add_children"
+	case OpTypeRemoveChild:
+		return "remove_child"
+	case OpTypeRemoveChildren:
+		return "remove_children"
+	case OpTypeGet:
+		return "get"
+	case OpTypeList:
+		return "list"
+	case OpTypeGetAttributes:
+		return "get_attributes"
+	case OpTypeSetAttributes:
+		return "set_attributes"
+	default:
+		return "unknown"
+	}
+}
+
+// ResourceOperationMap is a map, keyed by resource name, of maps, keyed by
+// OpType, of the API operations for that resource
+type ResourceOperationMap map[string]map[OpType]*awssdkmodel.Operation
 
 // GetOperationsForResource returns a map, keyed by OpType, for a supplied
 // resource. Resource name matching is case-insensitive.
-func (m resourceOperationMap) GetOperationsForResource(
+func (m ResourceOperationMap) GetOperationsForResource(
 	resName string,
 ) *map[OpType]*awssdkmodel.Operation {
 	for name, opMap := range m {
@@ -56,18 +95,28 @@ func (m resourceOperationMap) GetOperationsForResource(
 	return nil
 }
 
-// getResourceOperationMap returns a map, keyed by the resource name, of maps,
+// GetResourceOperationMap returns a map, keyed by the resource name, of maps,
 // keyed by OpType, of aws-sdk-go private/model/api.Operation struct pointers
 // that describe that Operation for that resource.
-func getResourceOperationMap(
+func GetResourceOperationMap(
 	ctx context.Context,
 	api *awssdkmodel.API,
 	cfg *config.Config,
-) resourceOperationMap {
-	// create an index of Operations by resource name and operation type
-	res := resourceOperationMap{}
-	for opID, op := range api.Operations {
+) ResourceOperationMap {
+	// create an index of Operations by resource name and operation type.
+	// Operation IDs are processed in sorted order so that the resulting map is
+	// the same no matter the order of the API's operations.
+	res := ResourceOperationMap{}
+	opIDs := lo.Keys(api.Operations)
+	sort.Strings(opIDs)
+	for _, opID := range opIDs {
+		op := api.Operations[opID]
 		opType, resName := getOpTypeAndResourceNameFromOpID(opID, cfg)
+		if isOperationIgnored(opID, resName, cfg) {
+			continue
+		}
+		// Collapse renamed resources into the resource that renames them
+		resName = cfg.GetResourceName(resName)
 		resOps := res.GetOperationsForResource(resName)
 		if resOps == nil {
 			resOps = &map[OpType]*awssdkmodel.Operation{}
@@ -86,7 +135,7 @@ func getResourceOperationMap(
 	// only and list that in our `operations:` configuration value.
 	for resName, rc := range cfg.GetResourceConfigs() {
 		arc := rc.ForAWS()
-		if arc == nil {
+		if arc == nil || cfg.GetIgnoreConfig().IsResourceIgnored(resName) {
 			continue
 		}
 		resOps := res.GetOperationsForResource(resName)
@@ -119,6 +168,21 @@ func getResourceOperationMap(
 	return res
 }
 
+// isOperationIgnored returns true if the supplied API operation, or the
+// resource that the operation was inferred to belong to (either by its
+// inferred name or the name that it is renamed to), is ignored in the
+// supplied Config
+func isOperationIgnored(
+	opID string,
+	resName string,
+	cfg *config.Config,
+) bool {
+	ic := cfg.GetIgnoreConfig()
+	return ic.IsOperationIgnored(opID) ||
+		ic.IsResourceIgnored(resName) ||
+		ic.IsResourceIgnored(cfg.GetResourceName(resName))
+}
+
 // getOpTypeAndResourceNameFromOpID guesses the resource name and type of
 // operation from the OperationID
 func getOpTypeAndResourceNameFromOpID(
@@ -221,7 +285,7 @@ func getOpTypeFromString(s string) OpType {
 	switch strings.ToLower(s) {
 	case "create":
 		return OpTypeCreate
-	case "createbatch":
+	case "createbatch", "create_batch":
 		return OpTypeCreateBatch
 	case "delete":
 		return OpTypeDelete
@@ -229,13 +293,13 @@ func getOpTypeFromString(s string) OpType {
 		return OpTypeReplace
 	case "update":
 		return OpTypeUpdate
-	case "addchild":
+	case "addchild", "add_child":
 		return OpTypeAddChild
-	case "addchildren":
+	case "addchildren", "add_children":
 		return OpTypeAddChildren
-	case "removechild":
+	case "removechild", "remove_child":
 		return OpTypeRemoveChild
-	case "removechildren":
+	case "removechildren", "remove_children":
 		return OpTypeRemoveChildren
 	case "get", "readone", "read_one":
 		return OpTypeGet
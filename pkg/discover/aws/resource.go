@@ -14,10 +14,16 @@ package aws
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"unicode"
 
 	"github.com/anydotcloud/grm/pkg/names"
 	"github.com/anydotcloud/grm/pkg/path/fieldpath"
+	"github.com/anydotcloud/grm/pkg/types/resource/schema"
 	awssdkmodel "github.com/aws/aws-sdk-go/private/model/api"
+	"github.com/gertd/go-pluralize"
+	"github.com/samber/lo"
 
 	"github.com/anydotcloud/grm-generate/pkg/config"
 	"github.com/anydotcloud/grm-generate/pkg/model"
@@ -25,7 +31,7 @@ import (
 
 // GetResourceDefinitionsForService returns a slice of `ResourceDefinition`
 // structs that describe the top-level resources discovered for a supplied AWS
-// service API
+// service API. The returned slice is sorted by Kind.
 func GetResourceDefinitionsForService(
 	ctx context.Context,
 	service string, // the service package name
@@ -34,36 +40,224 @@ func GetResourceDefinitionsForService(
 ) ([]*model.ResourceDefinition, error) {
 	res := []*model.ResourceDefinition{}
 
-	resOpMap := getResourceOperationMap(ctx, api, cfg)
+	resOpMap := GetResourceOperationMap(ctx, api, cfg)
 
-	for resName, ops := range resOpMap {
-		// For now, only care about resources with CREATE operations...
-		if _, found := ops[OpTypeCreate]; !found {
+	inferredNames := lo.Keys(resOpMap)
+	sort.Strings(inferredNames)
+	for _, resName := range inferredNames {
+		ops := resOpMap[resName]
+		if getResourceSkipReason(ops) != "" {
 			continue
 		}
-		resNames := names.New(resName)
-		kind := model.NewKind("aws", service, resNames.Camel)
 		rc := cfg.GetResourceConfig(resName)
+		kind := newKind(service, resName, rc)
 		rd := model.NewResourceDefinition(rc, kind)
-		err := AddFieldsToResourceDefinition(ctx, rd, rc, ops)
+		err := AddFieldsToResourceDefinition(ctx, rd, cfg, ops)
+		if err != nil {
+			return nil, err
+		}
+		addChildrenToResourceDefinition(service, rd, cfg, ops)
+		err = AddCustomFieldsToResourceDefinition(ctx, rd, cfg, api)
 		if err != nil {
 			return nil, err
 		}
 		res = append(res, rd)
 	}
+
+	// Now add any resources that are declared entirely in configuration
+	rcs := cfg.GetResourceConfigs()
+	resNames := lo.Keys(rcs)
+	sort.Strings(resNames)
+	for _, resName := range resNames {
+		rc := rcs[resName]
+		if !rc.IsCustom || cfg.GetIgnoreConfig().IsResourceIgnored(resName) {
+			continue
+		}
+		kind := newKind(service, resName, rc)
+		if lo.ContainsBy(res, func(rd *model.ResourceDefinition) bool {
+			return strings.EqualFold(rd.Kind.Name, kind.Name)
+		}) {
+			// The custom resource was also inferred from the API's
+			// operations and has already been processed above.
+			continue
+		}
+		rd := model.NewResourceDefinition(rc, kind)
+		if ops := resOpMap.GetOperationsForResource(resName); ops != nil {
+			err := AddFieldsToResourceDefinition(ctx, rd, cfg, *ops)
+			if err != nil {
+				return nil, err
+			}
+			addChildrenToResourceDefinition(service, rd, cfg, *ops)
+		}
+		err := AddCustomFieldsToResourceDefinition(ctx, rd, cfg, api)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, rd)
+	}
+	model.SortResourceDefinitions(res)
+	addReferencesToResourceDefinitions(res)
 	return res, nil
 }
 
+// newKind returns a Kind for the supplied service and resource name,
+// overriding the inferred plural name of the resource with any plural name in
+// the supplied ResourceConfig
+func newKind(
+	service string, // the service package name
+	resName string,
+	rc *config.ResourceConfig,
+) model.Kind {
+	kind := model.NewKind("aws", service, names.New(resName).Camel)
+	if rc != nil && rc.PluralName != nil {
+		kind.PluralName = *rc.PluralName
+	}
+	return kind
+}
+
+var (
+	// referenceFieldSuffixes contains the suffixes that, appended to a
+	// resource's name, form the name of a field that refers to that resource,
+	// e.g. "TableName" or "KmsKeyIds"
+	referenceFieldSuffixes = []string{
+		"Name", "Names", "ID", "IDs", "Arn", "Arns", "ARN", "ARNs",
+	}
+	// childOpPrefixes contains the prefixes of API operation IDs that add a
+	// child resource to or remove a child resource from a parent resource
+	childOpPrefixes = []string{
+		"Add", "Associate", "Attach", "Register", "Put", "Create", "Set",
+		"Remove", "Disassociate", "Detach", "Deregister", "Delete",
+	}
+	// childOpParentSeparators separates the child resource name from the
+	// parent resource name in API operation IDs like AddTagsToResource
+	childOpParentSeparators = []string{"To", "From", "With"}
+)
+
+// addChildrenToResourceDefinition adds the Kind of the child resource for
+// each of the supplied resource's add child and remove child operations to
+// the supplied ResourceDefinition.
+func addChildrenToResourceDefinition(
+	service string, // the service package name
+	rd *model.ResourceDefinition,
+	cfg *config.Config,
+	ops map[OpType]*awssdkmodel.Operation,
+) {
+	for _, opType := range []OpType{
+		OpTypeAddChild, OpTypeAddChildren,
+		OpTypeRemoveChild, OpTypeRemoveChildren,
+	} {
+		op, found := ops[opType]
+		if !found {
+			continue
+		}
+		childName := getChildResourceNameFromOpID(op.ExportedName)
+		childName = cfg.GetResourceName(childName)
+		kind := newKind(service, childName, cfg.GetResourceConfig(childName))
+		if lo.Contains(rd.Children, kind) {
+			continue
+		}
+		rd.Children = append(rd.Children, kind)
+	}
+	sort.Slice(rd.Children, func(i, j int) bool {
+		return rd.Children[i].Less(rd.Children[j])
+	})
+}
+
+// addReferencesToResourceDefinitions sets the References of each string or
+// list of strings field of the supplied resources whose name is the name of
+// another of the supplied resources in the same service followed by one of
+// the referenceFieldSuffixes. For example, the DynamoDB Backup resource's
+// TableName field refers to the Table resource. A resource's fields never
+// refer to the resource itself.
+func addReferencesToResourceDefinitions(
+	rds []*model.ResourceDefinition,
+) {
+	for _, rd := range rds {
+		for _, path := range rd.GetFieldPaths() {
+			def := rd.GetField(path).Definition
+			if def.Type != schema.FieldTypeString &&
+				(def.Type != schema.FieldTypeList ||
+					def.ElementType != schema.FieldTypeString) {
+				continue
+			}
+			for _, other := range rds {
+				if other.Kind == rd.Kind ||
+					other.Kind.CloudProvider != rd.Kind.CloudProvider ||
+					other.Kind.Service != rd.Kind.Service {
+					continue
+				}
+				if !lo.ContainsBy(
+					referenceFieldSuffixes, func(suffix string) bool {
+						return strings.EqualFold(
+							path.Back(), other.Kind.Name+suffix,
+						)
+					},
+				) {
+					continue
+				}
+				kind := other.Kind
+				def.References = &kind
+				break
+			}
+		}
+	}
+}
+
+// getChildResourceNameFromOpID guesses the name of the child resource from
+// the ID of an operation that adds a child resource to or removes a child
+// resource from a parent resource. For example, the child resource name for
+// both AttachRolePolicy and AddTagsToResource is guessed as "RolePolicy" and
+// "Tag" respectively.
+func getChildResourceNameFromOpID(opID string) string {
+	resName := opID
+	for _, prefix := range childOpPrefixes {
+		if strings.HasPrefix(resName, prefix) {
+			resName = strings.TrimPrefix(resName, prefix)
+			break
+		}
+	}
+	for _, sep := range childOpParentSeparators {
+		// The separator must begin a new word, e.g. the "To" in
+		// AddTagsToResource but not the "To" in AddTokens.
+		idx := strings.Index(resName, sep)
+		if idx > 0 && len(resName) > idx+len(sep) &&
+			unicode.IsUpper(rune(resName[idx+len(sep)])) {
+			resName = resName[:idx]
+			break
+		}
+	}
+	pluralize := pluralize.NewClient()
+	if pluralize.IsPlural(resName) {
+		return pluralize.Singular(resName)
+	}
+	return resName
+}
+
+// getResourceSkipReason returns a short description of why a resource having
+// the supplied operations should not be discovered, or the empty string if the
+// resource should be discovered.
+func getResourceSkipReason(
+	ops map[OpType]*awssdkmodel.Operation,
+) string {
+	// For now, only care about resources with CREATE operations...
+	if _, found := ops[OpTypeCreate]; !found {
+		return "no create operation"
+	}
+	return ""
+}
+
 // AddFieldsToResourceDefinition iterates over API Operations and a supplied
-// ResourceConfig and adds Fields to the supplied ResourceDefinition, recursing
-// down through any nested fields.
+// Config and adds Fields to the supplied ResourceDefinition, recursing down
+// through any nested fields. The Config's IgnoreConfig is stored on the
+// ResourceDefinition so that ignored field paths are skipped.
 func AddFieldsToResourceDefinition(
 	ctx context.Context,
 	rd *model.ResourceDefinition,
-	cfg *config.ResourceConfig,
+	cfg *config.Config,
 	ops map[OpType]*awssdkmodel.Operation,
 ) error {
 	rName := rd.Kind.Name
+	rd.Ignore = cfg.GetIgnoreConfig()
 
 	// We start with the Create operation's input and output shape. Members of
 	// the input shape are user-settable. Members of the output shape that are
@@ -87,8 +281,239 @@ func AddFieldsToResourceDefinition(
 				panic(msg)
 			}
 			path := fieldpath.FromString(memberName)
-			VisitMemberShape(ctx, rd, path, cfg, inputShape, memberShapeRef)
+			VisitMemberShape(
+				ctx, rd, path, rd.Config, inputShape, memberShapeRef,
+			)
 		}
 	}
+	addStatusFieldsToResourceDefinition(ctx, rd, ops)
 	return nil
 }
+
+// addStatusFieldsToResourceDefinition adds read-only Fields for the status
+// fields of the supplied ResourceDefinition that are only returned by the
+// Create or Get operations, such as the DynamoDB Table's TableStatus field.
+// When the resource's status is configured, the top-level field containing
+// the configured status field is added. Otherwise, top-level enum fields
+// whose names end with "Status" or "State" are added so that status rules can
+// be inferred from them.
+//
+// The members of an output shape describe the resource unless the output
+// shape has a single structure member, such as the CreateTable operation's
+// TableDescription member, in which case that member's members describe the
+// resource.
+func addStatusFieldsToResourceDefinition(
+	ctx context.Context,
+	rd *model.ResourceDefinition,
+	ops map[OpType]*awssdkmodel.Operation,
+) {
+	var statusName string
+	if sc := rd.Config.GetStatusConfig(); sc != nil {
+		statusName = fieldpath.FromString(sc.Path).At(0)
+	}
+	for _, opType := range []OpType{OpTypeCreate, OpTypeGet} {
+		op, found := ops[opType]
+		if !found || op.OutputRef.Shape == nil {
+			continue
+		}
+		shape := op.OutputRef.Shape
+		if len(shape.MemberRefs) == 1 {
+			for _, ref := range shape.MemberRefs {
+				if ref.Shape != nil && ref.Shape.Type == "structure" {
+					shape = ref.Shape
+				}
+			}
+		}
+		memberNames := lo.Keys(shape.MemberRefs)
+		sort.Strings(memberNames)
+		for _, memberName := range memberNames {
+			memberShapeRef := shape.MemberRefs[memberName]
+			name := names.New(memberName).Camel
+			if memberShapeRef.Shape == nil ||
+				rd.GetField(fieldpath.FromString(name)) != nil {
+				continue
+			}
+			if statusName != "" {
+				if !strings.EqualFold(name, statusName) {
+					continue
+				}
+			} else {
+				lower := strings.ToLower(name)
+				if !strings.HasSuffix(lower, "status") &&
+					!strings.HasSuffix(lower, "state") ||
+					len(memberShapeRef.Shape.Enum) == 0 {
+					continue
+				}
+			}
+			def := VisitMemberShape(
+				ctx, rd, fieldpath.FromString(memberName), rd.Config,
+				shape, memberShapeRef,
+			)
+			if def != nil {
+				def.IsReadOnly = true
+				def.IsRequired = false
+			}
+		}
+	}
+}
+
+// AddCustomFieldsToResourceDefinition adds a Field to the supplied
+// ResourceDefinition for each field configuration that does not match a field
+// already inferred from the API's operations. A custom field's definition is
+// built from the member shape referred to by the field configuration's `from`
+// attribute or, if there is no `from` attribute, from the type information in
+// the field configuration. The Config's IgnoreConfig is stored on the
+// ResourceDefinition so that ignored field paths are skipped.
+func AddCustomFieldsToResourceDefinition(
+	ctx context.Context,
+	rd *model.ResourceDefinition,
+	cfg *config.Config,
+	api *awssdkmodel.API,
+) error {
+	rName := rd.Kind.Name
+	rd.Ignore = cfg.GetIgnoreConfig()
+	fcs := rd.Config.GetFieldConfigs()
+	// Process field paths in sorted order so that containing fields are added
+	// before their nested fields.
+	pathStrs := lo.Keys(fcs)
+	sort.Strings(pathStrs)
+	for _, pathStr := range pathStrs {
+		fc := fcs[pathStr]
+		if rd.GetField(fieldpath.FromString(pathStr)) != nil {
+			continue
+		}
+		if !fc.IsCustom() {
+			// Field configs for renamed fields, or for fields that were
+			// ignored, do not match an inferred field. That is only a problem
+			// for resources that are declared entirely in configuration.
+			if rd.Config.IsCustom && len(fc.Renames) == 0 {
+				return fmt.Errorf(
+					"field %s of custom resource %s has neither a 'from' "+
+						"nor a 'type' attribute in config",
+					pathStr, rName,
+				)
+			}
+			continue
+		}
+		var containerShape *awssdkmodel.Shape
+		var shapeRef *awssdkmodel.ShapeRef
+		if fc.From != nil {
+			var err error
+			containerShape, shapeRef, err = getSourceShapeRef(api, fc.From)
+			if err != nil {
+				return fmt.Errorf(
+					"processing field %s of resource %s: %v",
+					pathStr, rName, err,
+				)
+			}
+		}
+		path := fieldpath.FromString(pathStr)
+		// Custom fields are not discovered from their containing field, so
+		// the nested custom fields of an ignored field are skipped here.
+		if rd.Ignore.IsFieldPathOrContainerIgnored(rName, path) {
+			continue
+		}
+		def := VisitMemberShape(
+			ctx, rd, path, rd.Config, containerShape, shapeRef,
+		)
+		if def == nil {
+			// the custom field is ignored...
+			continue
+		}
+		addMemberFieldDefinition(rd, fieldpath.FromString(pathStr), def)
+	}
+	return nil
+}
+
+// addMemberFieldDefinition adds the supplied FieldDefinition to the
+// MemberFieldDefinitions of the field containing the supplied field path, if
+// there is such a containing field.
+func addMemberFieldDefinition(
+	rd *model.ResourceDefinition,
+	path *fieldpath.Path,
+	def *model.FieldDefinition,
+) {
+	if path.Size() < 2 {
+		return
+	}
+	memberName := names.New(path.Pop()).Camel
+	container := rd.GetField(path)
+	if container == nil || container.Definition == nil {
+		return
+	}
+	if container.Definition.MemberFieldDefinitions == nil {
+		container.Definition.MemberFieldDefinitions = map[string]*model.FieldDefinition{}
+	}
+	container.Definition.MemberFieldDefinitions[memberName] = def
+}
+
+// getSourceShapeRef returns the ShapeRef, and the Shape containing that
+// ShapeRef, that is referred to by the supplied SourceFieldConfig. The
+// operation's Output shape is searched before its Input shape.
+func getSourceShapeRef(
+	api *awssdkmodel.API,
+	from *config.SourceFieldConfig,
+) (*awssdkmodel.Shape, *awssdkmodel.ShapeRef, error) {
+	op, found := api.Operations[from.Operation]
+	if !found {
+		return nil, nil, fmt.Errorf(
+			"operation %s in config 'from:' does not exist in API model",
+			from.Operation,
+		)
+	}
+	path := fieldpath.FromString(from.Path)
+	for _, shape := range []*awssdkmodel.Shape{
+		op.OutputRef.Shape, op.InputRef.Shape,
+	} {
+		containerShape, shapeRef := findMemberShapeRef(shape, path)
+		if shapeRef != nil {
+			return containerShape, shapeRef, nil
+		}
+	}
+	return nil, nil, fmt.Errorf(
+		"path %s in config 'from:' not found in Output or Input shape "+
+			"of operation %s",
+		from.Path, from.Operation,
+	)
+}
+
+// findMemberShapeRef walks the supplied field path down through the member
+// shapes of the supplied shape, diving into the element shapes of any list or
+// map shapes, and returns the ShapeRef at the end of the path along with the
+// Shape containing that ShapeRef. Member names are matched
+// case-insensitively against both the original and normalized member name.
+// Returns (nil, nil) if no member shape exists at the field path.
+func findMemberShapeRef(
+	shape *awssdkmodel.Shape,
+	path *fieldpath.Path,
+) (*awssdkmodel.Shape, *awssdkmodel.ShapeRef) {
+	var containerShape *awssdkmodel.Shape
+	var shapeRef *awssdkmodel.ShapeRef
+	for x := 0; x < path.Size(); x++ {
+		for shape != nil && (shape.Type == "list" || shape.Type == "map") {
+			if shape.Type == "list" {
+				shape = shape.MemberRef.Shape
+			} else {
+				shape = shape.ValueRef.Shape
+			}
+		}
+		if shape == nil || shape.Type != "structure" {
+			return nil, nil
+		}
+		part := path.At(x)
+		shapeRef = nil
+		for memberName, memberRef := range shape.MemberRefs {
+			if strings.EqualFold(memberName, part) ||
+				strings.EqualFold(names.New(memberName).Camel, part) {
+				shapeRef = memberRef
+				break
+			}
+		}
+		if shapeRef == nil {
+			return nil, nil
+		}
+		containerShape = shape
+		shape = shapeRef.Shape
+	}
+	return containerShape, shapeRef
+}
@@ -13,10 +13,13 @@ package aws_test
 
 import (
 	"context"
+	"encoding/json"
 	"sort"
 	"strings"
 	"testing"
 
+	"github.com/anydotcloud/grm/pkg/path/fieldpath"
+	"github.com/anydotcloud/grm/pkg/types/resource/schema"
 	awssdkmodel "github.com/aws/aws-sdk-go/private/model/api"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
@@ -104,6 +107,55 @@ func Test_GetResourceDefinitionForService_Kind(t *testing.T) {
 	assert.Equal([]string{"ecr"}, lo.Uniq(services))
 }
 
+func Test_GetResourceDefinitionForService_ResourceRenames(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	ctx := context.TODO()
+	service := "ecr"
+	api := apis[service]
+	require.NotNil(api, "expected non-nil API for ECR service")
+
+	cfg := config.New(
+		config.WithYAML(`
+resources:
+  Repo:
+    renames:
+      - Repository
+    plural_name: Repos
+    fields:
+      Name:
+        renames:
+          - RepositoryName
+`,
+		),
+	)
+	rds, err := aws.GetResourceDefinitionsForService(
+		ctx, service, api, cfg,
+	)
+	require.Nil(err)
+	require.Equal(2, len(rds))
+
+	rdsByName := map[string]*model.ResourceDefinition{}
+	for _, rd := range rds {
+		rdsByName[rd.Kind.Name] = rd
+	}
+	_, found := rdsByName["Repository"]
+	assert.False(found)
+
+	repo, found := rdsByName["Repo"]
+	require.True(found)
+	assert.Equal("Repos", repo.Kind.PluralName)
+	// The renamed resource's field configuration is used
+	_, found = repo.Fields["Name"]
+	assert.True(found)
+	_, found = repo.Fields["RepositoryName"]
+	assert.False(found)
+
+	rule, found := rdsByName["PullThroughCacheRule"]
+	require.True(found)
+	assert.Equal("PullThroughCacheRules", rule.Kind.PluralName)
+}
+
 func Test_GetResourceDefinitionForService_FieldPaths_NoConfig(t *testing.T) {
 	require := require.New(t)
 	assert := assert.New(t)
@@ -204,3 +256,584 @@ resources:
 	}
 	assert.Equal(expectFieldPaths, fieldPaths)
 }
+
+func Test_GetResourceDefinitionForService_IgnoreConfig(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	ctx := context.TODO()
+	service := "ecr"
+	api := apis[service]
+	require.NotNil(api, "expected non-nil API for ECR service")
+	require.Equal(api.PackageName(), "ecr")
+
+	cfg := config.New(
+		config.WithYAML(`
+ignore:
+  resources:
+    - PullThroughCacheRule
+  field_paths:
+    - Repository.ImageScanningConfiguration
+    - Repository.EncryptionConfiguration.KMS*
+    - "*.Tags"
+`,
+		),
+	)
+
+	rds, err := aws.GetResourceDefinitionsForService(
+		ctx, service, api, cfg,
+	)
+	require.Nil(err)
+	require.Equal(1, len(rds))
+
+	repoRD := rds[0]
+	assert.Equal("Repository", repoRD.Kind.Name)
+
+	fieldPaths := []string{}
+	for _, fPath := range repoRD.GetFieldPaths() {
+		fieldPaths = append(fieldPaths, fPath.String())
+	}
+	sort.Strings(fieldPaths)
+
+	expectFieldPaths := []string{
+		"EncryptionConfiguration",
+		"EncryptionConfiguration.EncryptionType",
+		"ImageTagMutability",
+		"RegistryID",
+		"RepositoryName",
+	}
+	assert.Equal(expectFieldPaths, fieldPaths)
+
+	// Ignored nested fields should not appear in the containing field's
+	// member field definitions either
+	encCfg := repoRD.GetField(fieldpath.FromString("EncryptionConfiguration"))
+	require.NotNil(encCfg)
+	assert.Equal(
+		[]string{"EncryptionType"},
+		lo.Keys(encCfg.Definition.MemberFieldDefinitions),
+	)
+}
+
+func Test_GetResourceDefinitionForService_CustomFields(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	ctx := context.TODO()
+	service := "ecr"
+	api := apis[service]
+	require.NotNil(api, "expected non-nil API for ECR service")
+	require.Equal(api.PackageName(), "ecr")
+
+	cfg := config.New(
+		config.WithYAML(`
+resources:
+  Repository:
+    fields:
+      RepositoryURI:
+        from:
+          operation: CreateRepository
+          path: Repository.RepositoryUri
+      Labels:
+        type: map
+        key_type: string
+        value_type: string
+      EncryptionConfiguration.Note:
+        type: string
+        is_read_only: true
+      Tags:
+        is_set: true
+`,
+		),
+	)
+
+	rds, err := aws.GetResourceDefinitionsForService(
+		ctx, service, api, cfg,
+	)
+	require.Nil(err)
+
+	var repoRD *model.ResourceDefinition
+	for _, rd := range rds {
+		if strings.EqualFold(rd.Kind.Name, "repository") {
+			repoRD = rd
+			break
+		}
+	}
+	require.NotNil(repoRD)
+
+	fieldPaths := []string{}
+	for _, fPath := range repoRD.GetFieldPaths() {
+		fieldPaths = append(fieldPaths, fPath.String())
+	}
+
+	expectFieldPaths := []string{
+		"EncryptionConfiguration",
+		"EncryptionConfiguration.EncryptionType",
+		"EncryptionConfiguration.KMSKey",
+		"EncryptionConfiguration.Note",
+		"ImageScanningConfiguration",
+		"ImageScanningConfiguration.ScanOnPush",
+		"ImageTagMutability",
+		"Labels",
+		"RegistryID",
+		"RepositoryName",
+		"RepositoryURI",
+		"Tags",
+		"Tags.Key",
+		"Tags.Value",
+	}
+	assert.Equal(expectFieldPaths, fieldPaths)
+
+	uri := repoRD.GetField(fieldpath.FromString("RepositoryURI"))
+	require.NotNil(uri)
+	assert.Equal(schema.FieldTypeString, uri.Definition.Type)
+
+	labels := repoRD.GetField(fieldpath.FromString("Labels"))
+	require.NotNil(labels)
+	assert.Equal(schema.FieldTypeMap, labels.Definition.Type)
+	assert.Equal(schema.FieldTypeString, labels.Definition.KeyType)
+	assert.Equal(schema.FieldTypeString, labels.Definition.ValueType)
+
+	// Custom nested fields are added to the containing field's member field
+	// definitions
+	encCfg := repoRD.GetField(fieldpath.FromString("EncryptionConfiguration"))
+	require.NotNil(encCfg)
+	note, found := encCfg.Definition.MemberFieldDefinitions["Note"]
+	require.True(found)
+	assert.True(note.IsReadOnly)
+
+	tags := repoRD.GetField(fieldpath.FromString("Tags"))
+	require.NotNil(tags)
+	assert.Equal(schema.FieldTypeList, tags.Definition.Type)
+	assert.True(tags.Definition.IsSet)
+}
+
+func Test_GetResourceDefinitionForService_CustomFieldsIgnored(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	ctx := context.TODO()
+	service := "ecr"
+	api := apis[service]
+	require.NotNil(api, "expected non-nil API for ECR service")
+
+	cfg := config.New(
+		config.WithYAML(`
+ignore:
+  field_paths:
+    - Repository.ImageScanningConfiguration
+resources:
+  Repository:
+    fields:
+      ImageScanningConfiguration.Note:
+        type: string
+      EncryptionConfiguration.Note:
+        type: string
+`,
+		),
+	)
+
+	rds, err := aws.GetResourceDefinitionsForService(
+		ctx, service, api, cfg,
+	)
+	require.Nil(err)
+
+	var repoRD *model.ResourceDefinition
+	for _, rd := range rds {
+		if strings.EqualFold(rd.Kind.Name, "repository") {
+			repoRD = rd
+			break
+		}
+	}
+	require.NotNil(repoRD)
+
+	// The custom nested field of an ignored field is skipped, while the
+	// custom nested field of a field that is not ignored is added
+	assert.Nil(repoRD.GetField(
+		fieldpath.FromString("ImageScanningConfiguration.Note"),
+	))
+	assert.NotNil(repoRD.GetField(
+		fieldpath.FromString("EncryptionConfiguration.Note"),
+	))
+}
+
+func Test_GetResourceDefinitionForService_CustomResource(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	ctx := context.TODO()
+	service := "ecr"
+	api := apis[service]
+	require.NotNil(api, "expected non-nil API for ECR service")
+	require.Equal(api.PackageName(), "ecr")
+
+	cfg := config.New(
+		config.WithYAML(`
+resources:
+  RepositoryPolicy:
+    is_custom: true
+    fields:
+      RepositoryName:
+        from:
+          operation: GetRepositoryPolicy
+          path: RepositoryName
+      PolicyText:
+        from:
+          operation: SetRepositoryPolicy
+          path: PolicyText
+      Force:
+        from:
+          operation: SetRepositoryPolicy
+          path: Force
+      Description:
+        type: string
+`,
+		),
+	)
+
+	rds, err := aws.GetResourceDefinitionsForService(
+		ctx, service, api, cfg,
+	)
+	require.Nil(err)
+	require.Equal(3, len(rds))
+
+	var policyRD *model.ResourceDefinition
+	for _, rd := range rds {
+		if rd.Kind.Name == "RepositoryPolicy" {
+			policyRD = rd
+			break
+		}
+	}
+	require.NotNil(policyRD)
+	assert.Equal("RepositoryPolicies", policyRD.Kind.PluralName)
+
+	fieldPaths := []string{}
+	for _, fPath := range policyRD.GetFieldPaths() {
+		fieldPaths = append(fieldPaths, fPath.String())
+	}
+	expectFieldPaths := []string{
+		"Description",
+		"Force",
+		"PolicyText",
+		"RepositoryName",
+	}
+	assert.Equal(expectFieldPaths, fieldPaths)
+
+	// PolicyText is found in SetRepositoryPolicy's Output shape, where it is
+	// not a required member
+	policyText := policyRD.GetField(fieldpath.FromString("PolicyText"))
+	require.NotNil(policyText)
+	assert.Equal(schema.FieldTypeString, policyText.Definition.Type)
+	assert.False(policyText.Definition.IsRequired)
+
+	// Force is only found in SetRepositoryPolicy's Input shape
+	force := policyRD.GetField(fieldpath.FromString("Force"))
+	require.NotNil(force)
+	assert.Equal(schema.FieldTypeBool, force.Definition.Type)
+
+	desc := policyRD.GetField(fieldpath.FromString("Description"))
+	require.NotNil(desc)
+	assert.Equal(schema.FieldTypeString, desc.Definition.Type)
+}
+
+func Test_GetResourceDefinitionForService_CustomResource_Errors(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.TODO()
+	service := "ecr"
+	api := apis[service]
+
+	tests := []struct {
+		name string
+		cfg  string
+	}{
+		{
+			"field with no from or type",
+			`
+resources:
+  RepositoryPolicy:
+    is_custom: true
+    fields:
+      PolicyText:
+        is_required: true
+`,
+		},
+		{
+			"from unknown operation",
+			`
+resources:
+  RepositoryPolicy:
+    is_custom: true
+    fields:
+      PolicyText:
+        from:
+          operation: GetPolicy
+          path: PolicyText
+`,
+		},
+		{
+			"from unknown path",
+			`
+resources:
+  RepositoryPolicy:
+    is_custom: true
+    fields:
+      PolicyText:
+        from:
+          operation: GetRepositoryPolicy
+          path: Policy.Text
+`,
+		},
+	}
+	for _, test := range tests {
+		cfg := config.New(config.WithYAML(test.cfg))
+		_, err := aws.GetResourceDefinitionsForService(
+			ctx, service, api, cfg,
+		)
+		assert.NotNil(err, test.name)
+	}
+}
+
+func Test_GetResourceDefinitionForService_FieldPaths_NestedRenamingConfig(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	ctx := context.TODO()
+	service := "ecr"
+	api := apis[service]
+	require.NotNil(api, "expected non-nil API for ECR service")
+	require.Equal(api.PackageName(), "ecr")
+
+	cfg := config.New(
+		config.WithYAML(`
+resources:
+  Repository:
+    fields:
+      Encryption:
+        renames:
+          - EncryptionConfiguration
+      Encryption.KMSKeyID:
+        renames:
+          - KmsKey
+      Tags.Name:
+        renames:
+          - Key
+`,
+		),
+	)
+
+	rds, err := aws.GetResourceDefinitionsForService(
+		ctx, service, api, cfg,
+	)
+	require.Nil(err)
+
+	var repoRD *model.ResourceDefinition
+	for _, rd := range rds {
+		if strings.EqualFold(rd.Kind.Name, "repository") {
+			repoRD = rd
+			break
+		}
+	}
+	require.NotNil(repoRD)
+
+	fieldPaths := []string{}
+	for _, fPath := range repoRD.GetFieldPaths() {
+		fieldPaths = append(fieldPaths, fPath.String())
+	}
+
+	expectFieldPaths := []string{
+		"Encryption",
+		"Encryption.EncryptionType",
+		"Encryption.KMSKeyID",
+		"ImageScanningConfiguration",
+		"ImageScanningConfiguration.ScanOnPush",
+		"ImageTagMutability",
+		"RegistryID",
+		"RepositoryName",
+		"Tags",
+		"Tags.Name",
+		"Tags.Value",
+	}
+	assert.Equal(expectFieldPaths, fieldPaths)
+
+	enc := repoRD.GetField(fieldpath.FromString("Encryption"))
+	require.NotNil(enc)
+	memberNames := lo.Keys(enc.Definition.MemberFieldDefinitions)
+	sort.Strings(memberNames)
+	assert.Equal([]string{"EncryptionType", "KMSKeyID"}, memberNames)
+}
+
+func Test_GetResourceDefinitionForService_JSON(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	ctx := context.TODO()
+	service := "ecr"
+	api := apis[service]
+	require.NotNil(api, "expected non-nil API for ECR service")
+
+	cfg := config.New(
+		config.WithYAML(`
+resources:
+  Repository:
+    fields:
+      Name:
+        renames:
+          - RepositoryName
+`,
+		),
+	)
+	rds, err := aws.GetResourceDefinitionsForService(
+		ctx, service, api, cfg,
+	)
+	require.Nil(err)
+
+	var repo *model.ResourceDefinition
+	for _, rd := range rds {
+		if rd.Kind.Name == "Repository" {
+			repo = rd
+		}
+	}
+	require.NotNil(repo)
+
+	b, err := json.Marshal(repo)
+	require.Nil(err)
+
+	got := struct {
+		Fields map[string]struct {
+			Path       string                 `json:"path"`
+			Config     map[string]interface{} `json:"config"`
+			Definition map[string]interface{} `json:"definition"`
+		} `json:"fields"`
+	}{}
+	require.Nil(json.Unmarshal(b, &got))
+
+	name, found := got.Fields["Name"]
+	require.True(found)
+	assert.Equal("Name", name.Path)
+	assert.Equal([]interface{}{"RepositoryName"}, name.Config["renames"])
+	assert.Equal("string", name.Definition["type"])
+	assert.Equal(true, name.Definition["is_required"])
+
+	scan, found := got.Fields["ImageScanningConfiguration.ScanOnPush"]
+	require.True(found)
+	assert.Equal("ImageScanningConfiguration.ScanOnPush", scan.Path)
+	assert.Nil(scan.Config)
+	assert.Equal("bool", scan.Definition["type"])
+}
+
+func Test_GetResourceDefinitionForService_Children(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	ctx := context.TODO()
+	service := "ecr"
+	api := apis[service]
+	require.NotNil(api, "expected non-nil API for ECR service")
+
+	cfg := config.New(
+		config.WithYAML(`
+resources:
+  Repository:
+    aws:
+      operations:
+        - type: add_child
+          id: PutLifecyclePolicy
+        - type: remove_child
+          id: DeleteLifecyclePolicy
+`,
+		),
+	)
+	rds, err := aws.GetResourceDefinitionsForService(
+		ctx, service, api, cfg,
+	)
+	require.Nil(err)
+
+	children := map[string][]string{}
+	for _, rd := range rds {
+		for _, child := range rd.Children {
+			children[rd.Kind.Name] = append(children[rd.Kind.Name], child.Name)
+		}
+	}
+	// Both the add and remove operations refer to the same child resource
+	assert.Equal(
+		map[string][]string{
+			"Repository": {"LifecyclePolicy"},
+		},
+		children,
+	)
+}
+
+func Test_GetResourceDefinitionForService_References(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	ctx := context.TODO()
+	service := "dynamodb"
+	api := apis[service]
+	require.NotNil(api, "expected non-nil API for DynamoDB service")
+
+	rds, err := aws.GetResourceDefinitionsForService(
+		ctx, service, api, config.New(),
+	)
+	require.Nil(err)
+
+	references := map[string]string{}
+	for _, rd := range rds {
+		for _, path := range rd.GetFieldPaths() {
+			ref := rd.GetField(path).Definition.References
+			if ref == nil {
+				continue
+			}
+			references[rd.Kind.Name+"."+path.String()] = ref.Name
+		}
+	}
+	// Table.TableName names the Table itself and GlobalTable.GlobalTableName
+	// does not name the Table resource, so neither refers to Table
+	assert.Equal(
+		map[string]string{
+			"Backup.TableName": "Table",
+		},
+		references,
+	)
+}
+
+func Test_GetResourceDefinitionForService_StatusFields(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	ctx := context.TODO()
+	service := "dynamodb"
+	api := apis[service]
+	require.NotNil(api, "expected non-nil API for DynamoDB service")
+
+	getTable := func(cfg *config.Config) *model.ResourceDefinition {
+		rds, err := aws.GetResourceDefinitionsForService(
+			ctx, service, api, cfg,
+		)
+		require.Nil(err)
+		table, found := lo.Find(rds, func(rd *model.ResourceDefinition) bool {
+			return rd.Kind.Name == "Table"
+		})
+		require.True(found)
+		return table
+	}
+
+	// Status-like enum fields only returned by the CreateTable operation
+	// are discovered as read-only fields
+	table := getTable(nil)
+	f := table.GetField(fieldpath.FromString("TableStatus"))
+	require.NotNil(f)
+	assert.True(f.Definition.IsReadOnly)
+	assert.False(f.Definition.IsRequired)
+	assert.Contains(f.Definition.EnumValues, "ACTIVE")
+	assert.Nil(table.GetField(fieldpath.FromString("ArchivalSummary")))
+
+	// A configured status field is discovered, along with its containing
+	// field, instead
+	table = getTable(config.New(
+		config.WithYAML(`
+resources:
+  Table:
+    status:
+      path: ArchivalSummary.ArchivalReason
+`,
+		),
+	))
+	assert.Nil(table.GetField(fieldpath.FromString("TableStatus")))
+	f = table.GetField(fieldpath.FromString("ArchivalSummary"))
+	require.NotNil(f)
+	assert.True(f.Definition.IsReadOnly)
+	require.NotNil(
+		table.GetField(fieldpath.FromString("ArchivalSummary.ArchivalReason")),
+	)
+}
@@ -0,0 +1,64 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package testutil contains helpers for tests that need resources discovered
+// from the AWS API models in the pkg/discover/aws/testdata directory.
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/anydotcloud/grm-generate/pkg/config"
+	discover "github.com/anydotcloud/grm-generate/pkg/discover/aws"
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+// APIModelDir returns the absolute path to the directory containing the
+// testdata API models
+func APIModelDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "testdata")
+}
+
+// DiscoverResources returns the resources, sorted by Kind, discovered in the
+// testdata API models of the supplied services, such as "ecr", using the
+// supplied configuration for every service
+func DiscoverResources(
+	t *testing.T,
+	cfg *config.Config,
+	services ...string,
+) []*model.ResourceDefinition {
+	require := require.New(t)
+	ctx := context.TODO()
+	dir := APIModelDir()
+	paths := make([]string, len(services))
+	for x, service := range services {
+		paths[x] = filepath.Join(dir, fmt.Sprintf("%s-api.json", service))
+	}
+	apis, err := discover.GetAPIs(ctx, dir, paths)
+	require.Nil(err)
+	res := []*model.ResourceDefinition{}
+	for _, service := range services {
+		rds, err := discover.GetResourceDefinitionsForService(
+			ctx, service, apis[service], cfg,
+		)
+		require.Nil(err)
+		res = append(res, rds...)
+	}
+	model.SortResourceDefinitions(res)
+	return res
+}
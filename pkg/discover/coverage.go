@@ -0,0 +1,66 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package discover
+
+import (
+	"context"
+)
+
+// ResourceCoverage describes the API operations that were associated with a
+// single inferred resource and, if the resource was not discovered, why it
+// was skipped.
+type ResourceCoverage struct {
+	// Name is the inferred name of the resource
+	Name string `json:"name"`
+	// Operations is a map, keyed by stringified operation type, of the ID of
+	// the API operation serving that operation type for the resource
+	Operations map[string]string `json:"operations"`
+	// SkipReason is empty if the resource was discovered. Otherwise, it
+	// contains a short description of why the resource was skipped.
+	SkipReason string `json:"skip_reason,omitempty"`
+}
+
+// IsSkipped returns true if the resource was not discovered
+func (c *ResourceCoverage) IsSkipped() bool {
+	return c.SkipReason != ""
+}
+
+// ServiceCoverage describes how much of a cloud service API was claimed by
+// discovered resources.
+type ServiceCoverage struct {
+	// Service is the name of the cloud service
+	Service string `json:"service"`
+	// Resources contains the coverage information for every resource name
+	// that was inferred from the service API, sorted by resource name
+	Resources []*ResourceCoverage `json:"resources"`
+	// OrphanOperations contains the sorted IDs of API operations that could
+	// not be associated with any inferred resource
+	OrphanOperations []string `json:"orphan_operations,omitempty"`
+	// IgnoredOperations contains the sorted IDs of API operations that were
+	// ignored by configuration, either directly or because the resource they
+	// would be associated with is ignored
+	IgnoredOperations []string `json:"ignored_operations,omitempty"`
+	// TotalOperations is the number of operations in the service API
+	TotalOperations int `json:"total_operations"`
+	// ClaimedOperations is the number of API operations that are associated
+	// with a discovered (i.e. not skipped) resource
+	ClaimedOperations int `json:"claimed_operations"`
+	// ClaimedPercent is the percentage of API operations that are associated
+	// with a discovered resource
+	ClaimedPercent float64 `json:"claimed_percent"`
+}
+
+// ReportsCoverage provides a standard interface for reporting on how much of
+// a cloud service API is covered by resource discovery
+type ReportsCoverage interface {
+	ReportCoverage(context.Context) ([]*ServiceCoverage, error)
+}
@@ -17,7 +17,9 @@ import (
 	"github.com/anydotcloud/grm-generate/pkg/model"
 )
 
-// DiscoversResources provides a standard interface for resource discovery
+// DiscoversResources provides a standard interface for resource discovery.
+// Implementations return ResourceDefinitions sorted by Kind so that the
+// output of discovery is the same from run to run.
 type DiscoversResources interface {
 	DiscoverResources(context.Context) ([]*model.ResourceDefinition, error)
 }
@@ -0,0 +1,266 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package docs
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+const (
+	// FormatMarkdown renders reference documentation as Markdown
+	FormatMarkdown = "markdown"
+	// FormatHTML renders reference documentation as HTML
+	FormatHTML = "html"
+)
+
+var (
+	// Formats contains the supported reference documentation formats
+	Formats = []string{
+		FormatMarkdown,
+		FormatHTML,
+	}
+)
+
+// executor is implemented by both text/template.Template and
+// html/template.Template
+type executor interface {
+	ExecuteTemplate(io.Writer, string, interface{}) error
+}
+
+// Page describes a single rendered documentation page
+type Page struct {
+	// Path is the path to the page, relative to the documentation root
+	// directory
+	Path string
+	// Contents is the rendered page
+	Contents []byte
+}
+
+// resourcePage is the data passed to the resource page template
+type resourcePage struct {
+	Kind   model.Kind
+	Fields []fieldRow
+}
+
+// fieldRow describes a single field in a resource page's field tree
+type fieldRow struct {
+	// Name is the last part of the field path
+	Name string
+	// Path is the stringified field path
+	Path string
+	// Depth is the number of containing fields
+	Depth int
+	// Type is a human-readable description of the field's type
+	Type string
+	// Flags contains the names of the field's boolean attributes that are
+	// true, e.g. "required"
+	Flags []string
+	// Documentation contains the field's description
+	Documentation string
+}
+
+// indexPage is the data passed to the service index page template
+type indexPage struct {
+	CloudProvider string
+	Service       string
+	Resources     []indexEntry
+}
+
+// indexEntry describes a single resource in a service index page
+type indexEntry struct {
+	Kind      model.Kind
+	Link      string
+	NumFields int
+}
+
+// RenderPages returns the reference documentation pages, in the supplied
+// format, for the supplied resources. One page is returned for each resource
+// along with an index page for each service. Pages for a service are placed
+// in a "<cloud provider>/<service>" directory.
+//
+// Resource identifiers are not yet discovered, so each resource page notes
+// that its identifying fields are unknown rather than listing them.
+func RenderPages(
+	format string,
+	rds []*model.ResourceDefinition,
+) ([]*Page, error) {
+	tpl, err := getTemplates(format)
+	if err != nil {
+		return nil, err
+	}
+	ext := fileExtension(format)
+	// Resources are grouped by service, preserving the order of the supplied
+	// resources
+	services := []string{}
+	byService := map[string][]*model.ResourceDefinition{}
+	for _, rd := range rds {
+		svcDir := filepath.Join(rd.Kind.CloudProvider, rd.Kind.Service)
+		if _, found := byService[svcDir]; !found {
+			services = append(services, svcDir)
+		}
+		byService[svcDir] = append(byService[svcDir], rd)
+	}
+	res := []*Page{}
+	for _, svcDir := range services {
+		svcRDs := byService[svcDir]
+		index := indexPage{
+			CloudProvider: svcRDs[0].Kind.CloudProvider,
+			Service:       svcRDs[0].Kind.Service,
+		}
+		for _, rd := range svcRDs {
+			fileName := strings.ToLower(rd.Kind.Name) + ext
+			var b strings.Builder
+			if err := tpl.ExecuteTemplate(
+				&b, "resource", newResourcePage(rd),
+			); err != nil {
+				return nil, fmt.Errorf(
+					"failed to render documentation for %s: %s",
+					rd.Kind.Name, err,
+				)
+			}
+			res = append(res, &Page{
+				Path:     filepath.Join(svcDir, fileName),
+				Contents: []byte(b.String()),
+			})
+			index.Resources = append(index.Resources, indexEntry{
+				Kind:      rd.Kind,
+				Link:      fileName,
+				NumFields: len(rd.Fields),
+			})
+		}
+		var b strings.Builder
+		if err := tpl.ExecuteTemplate(&b, "index", index); err != nil {
+			return nil, fmt.Errorf(
+				"failed to render documentation index for %s: %s",
+				svcDir, err,
+			)
+		}
+		res = append(res, &Page{
+			Path:     filepath.Join(svcDir, "index"+ext),
+			Contents: []byte(b.String()),
+		})
+	}
+	return res, nil
+}
+
+// WritePages writes the supplied documentation pages to files underneath the
+// supplied root directory, creating directories as needed
+func WritePages(
+	rootPath string,
+	pages []*Page,
+) error {
+	for _, page := range pages {
+		path := filepath.Join(rootPath, page.Path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, page.Contents, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newResourcePage returns the template data for a resource's page. Fields are
+// ordered by field path so that nested fields immediately follow their
+// containing field.
+func newResourcePage(rd *model.ResourceDefinition) resourcePage {
+	res := resourcePage{Kind: rd.Kind}
+	for _, path := range rd.GetFieldPaths() {
+		f := rd.GetField(path)
+		res.Fields = append(res.Fields, fieldRow{
+			Name:          path.Back(),
+			Path:          path.String(),
+			Depth:         path.Size() - 1,
+			Type:          f.Definition.TypeString(),
+			Flags:         flags(f.Definition),
+			Documentation: f.Definition.Documentation,
+		})
+	}
+	return res
+}
+
+// flags returns the names of the boolean attributes of the supplied field
+// definition that are true
+func flags(def *model.FieldDefinition) []string {
+	res := []string{}
+	if def.IsRequired {
+		res = append(res, "required")
+	}
+	if def.IsReadOnly {
+		res = append(res, "read-only")
+	}
+	if def.IsImmutable {
+		res = append(res, "immutable")
+	}
+	if def.IsSecret {
+		res = append(res, "secret")
+	}
+	if def.IsLateInitialized {
+		res = append(res, "late-initialized")
+	}
+	return res
+}
+
+// fileExtension returns the file extension for pages in the supplied format
+func fileExtension(format string) string {
+	if format == FormatHTML {
+		return ".html"
+	}
+	return ".md"
+}
+
+// getTemplates returns the parsed templates for the supplied format
+func getTemplates(format string) (executor, error) {
+	switch format {
+	case FormatMarkdown:
+		return texttemplate.Must(
+			texttemplate.New("markdown").Funcs(texttemplate.FuncMap{
+				"indent":     markdownIndent,
+				"join":       strings.Join,
+				"escapeCell": markdownEscapeCell,
+			}).Parse(markdownTemplates),
+		), nil
+	case FormatHTML:
+		return htmltemplate.Must(
+			htmltemplate.New("html").Funcs(htmltemplate.FuncMap{
+				"join": strings.Join,
+			}).Parse(htmlTemplates),
+		), nil
+	}
+	return nil, fmt.Errorf(
+		"unsupported documentation format %q. Supported formats are: %s",
+		format, strings.Join(Formats, ", "),
+	)
+}
+
+// markdownIndent returns a prefix that visually indents a Markdown table cell
+// to the supplied depth
+func markdownIndent(depth int) string {
+	return strings.Repeat("&nbsp;&nbsp;&nbsp;&nbsp;", depth)
+}
+
+// markdownEscapeCell escapes the supplied string so that it can be placed in
+// a single Markdown table cell
+func markdownEscapeCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "\n\n", "<br><br>")
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,122 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package docs_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anydotcloud/grm/pkg/path/fieldpath"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anydotcloud/grm-generate/pkg/config"
+	"github.com/anydotcloud/grm-generate/pkg/discover/aws/testutil"
+	"github.com/anydotcloud/grm-generate/pkg/docs"
+)
+
+var (
+	// ecrConfig marks fields of the ECR Repository resource with flags that
+	// are not inferred from the API model
+	ecrConfig = config.New(
+		config.WithYAML(`
+resources:
+  Repository:
+    fields:
+      RepositoryName:
+        is_immutable: true
+      EncryptionConfiguration.KMSKey:
+        is_secret: true
+`,
+		),
+	)
+)
+
+func TestRenderPages_Markdown(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	rds := testutil.DiscoverResources(t, ecrConfig, "ecr")
+	rds[1].GetField(fieldpath.FromString("RepositoryName")).Definition.Documentation =
+		"The name of the repository.\n\nMust be | unique."
+	pages, err := docs.RenderPages(docs.FormatMarkdown, rds)
+	require.Nil(err)
+	require.Len(pages, 3)
+
+	assert.Equal("aws/ecr/pullthroughcacherule.md", pages[0].Path)
+	assert.Equal("aws/ecr/repository.md", pages[1].Path)
+	got := string(pages[1].Contents)
+	assert.Contains(got, "# Repository\n")
+	assert.Contains(got, "| aws | ecr | Repository | Repositories |")
+	assert.Contains(
+		got, "## Identifiers\n\nIdentifying fields are not yet discovered.\n",
+	)
+	assert.Contains(
+		got,
+		"| `RepositoryName` | string | required, immutable | "+
+			"The name of the repository.<br><br>Must be \\| unique. |",
+	)
+	assert.Contains(
+		got,
+		"| &nbsp;&nbsp;&nbsp;&nbsp;`KMSKey` | string | secret |  |",
+	)
+	assert.Contains(got, "| `Tags` | []struct |  |  |")
+	// Nested fields immediately follow their containing field
+	assert.Less(
+		strings.Index(got, "`EncryptionConfiguration`"), strings.Index(got, "`KMSKey`"),
+	)
+	assert.Less(
+		strings.Index(got, "`KMSKey`"), strings.Index(got, "`ImageScanningConfiguration`"),
+	)
+
+	assert.Equal("aws/ecr/index.md", pages[2].Path)
+	assert.Contains(
+		string(pages[2].Contents),
+		"| [Repository](repository.md) | Repositories | 11 |",
+	)
+}
+
+func TestRenderPages_HTML(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	rds := testutil.DiscoverResources(t, ecrConfig, "ecr")
+	rds[1].GetField(fieldpath.FromString("Tags")).Definition.Documentation = "<script>"
+	pages, err := docs.RenderPages(docs.FormatHTML, rds)
+	require.Nil(err)
+	require.Len(pages, 3)
+
+	assert.Equal("aws/ecr/repository.html", pages[1].Path)
+	got := string(pages[1].Contents)
+	assert.Contains(got, "<h1>Repository</h1>")
+	assert.Contains(
+		got, "<h2>Identifiers</h2>\n<p>Identifying fields are not yet discovered.</p>",
+	)
+	assert.Contains(
+		got,
+		`<td style="padding-left: 1em"><code>KMSKey</code></td>`,
+	)
+	assert.Contains(got, "&lt;script&gt;")
+	assert.NotContains(got, "<script>")
+
+	assert.Equal("aws/ecr/index.html", pages[2].Path)
+	assert.Contains(
+		string(pages[2].Contents),
+		`<a href="repository.html">Repository</a>`,
+	)
+}
+
+func TestRenderPages_UnknownFormat(t *testing.T) {
+	assert := assert.New(t)
+	_, err := docs.RenderPages("pdf", nil)
+	assert.NotNil(err)
+}
@@ -0,0 +1,93 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package docs
+
+const (
+	markdownTemplates = `
+{{- define "resource" -}}
+# {{ .Kind.Name }}
+
+| Cloud Provider | Service | Name | Plural Name |
+| --- | --- | --- | --- |
+| {{ .Kind.CloudProvider }} | {{ .Kind.Service }} | {{ .Kind.Name }} | {{ .Kind.PluralName }} |
+
+## Identifiers
+
+Identifying fields are not yet discovered.
+
+## Fields
+
+| Field | Type | Flags | Description |
+| --- | --- | --- | --- |
+{{- range .Fields }}
+| {{ indent .Depth }}` + "`{{ .Name }}`" + ` | {{ .Type }} | {{ join .Flags ", " }} | {{ escapeCell .Documentation }} |
+{{- end }}
+{{ end -}}
+
+{{- define "index" -}}
+# {{ .Service }} ({{ .CloudProvider }})
+
+| Resource | Plural Name | Fields |
+| --- | --- | --- |
+{{- range .Resources }}
+| [{{ .Kind.Name }}]({{ .Link }}) | {{ .Kind.PluralName }} | {{ .NumFields }} |
+{{- end }}
+{{ end -}}
+`
+
+	htmlTemplates = `
+{{- define "resource" -}}
+<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Kind.Name }}</title>
+</head>
+<body>
+<h1>{{ .Kind.Name }}</h1>
+<table>
+<tr><th>Cloud Provider</th><th>Service</th><th>Name</th><th>Plural Name</th></tr>
+<tr><td>{{ .Kind.CloudProvider }}</td><td>{{ .Kind.Service }}</td><td>{{ .Kind.Name }}</td><td>{{ .Kind.PluralName }}</td></tr>
+</table>
+<h2>Identifiers</h2>
+<p>Identifying fields are not yet discovered.</p>
+<h2>Fields</h2>
+<table>
+<tr><th>Field</th><th>Type</th><th>Flags</th><th>Description</th></tr>
+{{- range .Fields }}
+<tr id="{{ .Path }}"><td style="padding-left: {{ .Depth }}em"><code>{{ .Name }}</code></td><td>{{ .Type }}</td><td>{{ join .Flags ", " }}</td><td>{{ .Documentation }}</td></tr>
+{{- end }}
+</table>
+</body>
+</html>
+{{ end -}}
+
+{{- define "index" -}}
+<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Service }} ({{ .CloudProvider }})</title>
+</head>
+<body>
+<h1>{{ .Service }} ({{ .CloudProvider }})</h1>
+<table>
+<tr><th>Resource</th><th>Plural Name</th><th>Fields</th></tr>
+{{- range .Resources }}
+<tr><td><a href="{{ .Link }}">{{ .Kind.Name }}</a></td><td>{{ .Kind.PluralName }}</td><td>{{ .NumFields }}</td></tr>
+{{- end }}
+</table>
+</body>
+</html>
+{{ end -}}
+`
+)
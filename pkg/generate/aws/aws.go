@@ -10,3 +10,99 @@
 // permissions and limitations under the License.
 
 package aws
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	awssdkmodel "github.com/aws/aws-sdk-go/private/model/api"
+
+	discover "github.com/anydotcloud/grm-generate/pkg/discover/aws"
+	"github.com/anydotcloud/grm-generate/pkg/generate"
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+const (
+	tplSDK = "resource/aws/sdk.go.tpl"
+	// sdkPackageBase is the Go import path of the directory containing the
+	// aws-sdk-go service packages
+	sdkPackageBase = "github.com/aws/aws-sdk-go/service"
+)
+
+// sdkGenerator renders, for each AWS resource, the Go functions that call
+// the resource's API operations. It implements the `pkg/generate.Generator`
+// interface.
+type sdkGenerator struct {
+	opts option
+	// apis is a map, keyed by service model package name, of API structs
+	// representing the operations and shapes of that service's API.
+	apis map[string]*awssdkmodel.API
+}
+
+func (g *sdkGenerator) Generate(
+	ctx context.Context,
+	rds []*model.ResourceDefinition,
+) ([]*generate.File, error) {
+	fsys, err := generate.NewTemplateFS(g.opts.templateDirs...)
+	if err != nil {
+		return nil, err
+	}
+	tpls, err := generate.LoadGoTemplates(
+		fsys, g.opts.cfg.GetBoilerplateConfig(), tplSDK,
+	)
+	if err != nil {
+		return nil, err
+	}
+	// opMaps is a map, keyed by service model package name, of the
+	// operations for each resource in that service's API
+	opMaps := map[string]discover.ResourceOperationMap{}
+	res := []*generate.File{}
+	for _, rd := range rds {
+		service := rd.Kind.Service
+		api, found := g.apis[service]
+		if !found {
+			return nil, fmt.Errorf(
+				"no API model loaded for service %s of resource %s",
+				service, rd.Kind.Name,
+			)
+		}
+		opMap, found := opMaps[service]
+		if !found {
+			opMap = discover.GetResourceOperationMap(ctx, api, g.opts.cfg)
+			opMaps[service] = opMap
+		}
+		ops := opMap.GetOperationsForResource(rd.Kind.Name)
+		if ops == nil {
+			// Resources declared entirely in configuration may have no
+			// API operations to call
+			continue
+		}
+		resDir := path.Join(service, strings.ToLower(rd.Kind.Name))
+		f, err := generate.RenderFile(
+			tpls, tplSDK, rd.Kind,
+			path.Join(resDir, g.opts.apiVersion, "sdk.go"),
+			newSDKData(g.opts.apiVersion, rd, *ops),
+		)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, f)
+	}
+	return res, nil
+}
+
+// NewGenerator returns a new Generator that renders, for each AWS resource,
+// Go functions that build the inputs to the resource's create, get, update
+// and delete API operations from the resource's field values and copy the
+// outputs of those operations back into the resource's fields
+func NewGenerator(
+	apis map[string]*awssdkmodel.API,
+	opts ...option,
+) generate.Generator {
+	return &sdkGenerator{
+		opts: mergeOptions(opts),
+		apis: apis,
+	}
+}
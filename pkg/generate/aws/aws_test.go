@@ -0,0 +1,191 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package aws_test
+
+import (
+	"context"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	awssdkmodel "github.com/aws/aws-sdk-go/private/model/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anydotcloud/grm-generate/pkg/config"
+	discover "github.com/anydotcloud/grm-generate/pkg/discover/aws"
+	"github.com/anydotcloud/grm-generate/pkg/generate"
+	"github.com/anydotcloud/grm-generate/pkg/generate/aws"
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+var (
+	apiModelDir, _ = filepath.Abs(
+		filepath.Join("..", "..", "discover", "aws", "testdata"),
+	)
+	services = []string{
+		"dynamodb",
+		"ec2",
+		"ecr",
+		"lambda",
+		"s3",
+	}
+	apis map[string]*awssdkmodel.API
+)
+
+func init() {
+	ctx := context.TODO()
+	apiModelPaths := []string{}
+	for _, service := range services {
+		apiModelPaths = append(
+			apiModelPaths,
+			filepath.Join(apiModelDir, fmt.Sprintf("%s-api.json", service)),
+		)
+	}
+	sapis, err := discover.GetAPIs(ctx, apiModelDir, apiModelPaths)
+	if err != nil {
+		panic(err)
+	}
+	apis = sapis
+}
+
+// generateSDKFile returns the contents of the generated sdk.go file for the
+// named resource of the supplied service
+func generateSDKFile(
+	t *testing.T,
+	service string,
+	resName string,
+	cfg *config.Config,
+) string {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	rds, err := discover.GetResourceDefinitionsForService(
+		ctx, service, apis[service], cfg,
+	)
+	require.Nil(err)
+
+	gen := aws.NewGenerator(apis, aws.WithConfig(cfg))
+	files, err := gen.Generate(ctx, rds)
+	require.Nil(err)
+
+	var found *generate.File
+	for _, f := range files {
+		if f.Path == filepath.Join(service, resName, "v1", "sdk.go") {
+			found = f
+		}
+	}
+	require.NotNil(found)
+
+	_, err = parser.ParseFile(
+		token.NewFileSet(), found.Path, found.Contents, parser.AllErrors,
+	)
+	require.Nil(err)
+	return string(found.Contents)
+}
+
+func TestGenerator(t *testing.T) {
+	assert := assert.New(t)
+
+	contents := generateSDKFile(t, "ecr", "repository", nil)
+
+	// ECR has no OpTypeGet or OpTypeUpdate operation for repositories
+	for _, expect := range []string{
+		"func (r *Repository) NewCreateInput() *svcsdk.CreateRepositoryInput {",
+		"func (r *Repository) SetFromCreateOutput(out *svcsdk.CreateRepositoryOutput) error {",
+		"func (r *Repository) NewDeleteInput() *svcsdk.DeleteRepositoryInput {",
+		"func (r *Repository) SetFromDeleteOutput(out *svcsdk.DeleteRepositoryOutput) error {",
+		// scalar fields are copied as pointers into the input...
+		"if v10 := r.GetRepositoryName(); v10 != nil {\n\t\tres.RepositoryName = v10\n",
+		// ...and as values out of the output's wrapping member
+		"if out == nil || out.Repository == nil {",
+		"if err := r.SetRepositoryName(*v26); err != nil {",
+		// struct fields are copied member by member
+		"f2 := &svcsdk.EncryptionConfiguration{}",
+		"f2.KmsKey = v4",
+		"f18 := &RepositoryEncryptionConfiguration{}",
+		"f18.KMSKey = v20",
+		// list of struct fields are copied element by element
+		"f12 := make([]*svcsdk.Tag, 0, len(v11))",
+	} {
+		assert.Contains(contents, expect)
+	}
+	assert.NotContains(contents, "NewGetInput")
+	assert.NotContains(contents, "NewUpdateInput")
+	assert.NotContains(contents, "github.com/aws/aws-sdk-go/aws\"")
+
+	contents = generateSDKFile(t, "dynamodb", "table", nil)
+
+	for _, expect := range []string{
+		"svcsdk \"github.com/aws/aws-sdk-go/service/dynamodb\"",
+		"func (r *Table) NewGetInput() *svcsdk.DescribeTableInput {",
+		"func (r *Table) NewUpdateInput() *svcsdk.UpdateTableInput {",
+		"if out == nil || out.TableDescription == nil {",
+		// lists of scalars are converted with the aws package
+		"\"github.com/aws/aws-sdk-go/aws\"",
+		"f20.NonKeyAttributes = aws.StringSlice(v21)",
+	} {
+		assert.Contains(contents, expect)
+	}
+}
+
+func TestGeneratorRenames(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := config.New(
+		config.WithYAML(`
+resources:
+  Repository:
+    fields:
+      Name:
+        renames:
+          - RepositoryName
+      Encryption:
+        renames:
+          - EncryptionConfiguration
+      Encryption.KMSKeyID:
+        renames:
+          - KmsKey
+`,
+		),
+	)
+	contents := generateSDKFile(t, "ecr", "repository", cfg)
+
+	for _, expect := range []string{
+		"if v10 := r.GetName(); v10 != nil {\n\t\tres.RepositoryName = v10\n",
+		"if v1 := r.GetEncryption(); v1 != nil {",
+		"if v4 := v1.KMSKeyID; v4 != nil {\n\t\t\tf2.KmsKey = v4\n",
+		"f18 := &RepositoryEncryption{}",
+		"if v20 := v17.KmsKey; v20 != nil {\n\t\t\tf18.KMSKeyID = v20\n",
+		"if err := r.SetName(*v26); err != nil {",
+	} {
+		assert.Contains(contents, expect)
+	}
+	assert.NotContains(contents, "GetRepositoryName")
+}
+
+func TestGeneratorNoAPI(t *testing.T) {
+	require := require.New(t)
+
+	gen := aws.NewGenerator(apis)
+	_, err := gen.Generate(
+		context.TODO(),
+		[]*model.ResourceDefinition{
+			model.NewResourceDefinition(
+				nil, model.NewKind("aws", "sqs", "Queue"),
+			),
+		},
+	)
+	require.NotNil(err)
+}
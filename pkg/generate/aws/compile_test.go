@@ -0,0 +1,370 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package aws_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	discover "github.com/anydotcloud/grm-generate/pkg/discover/aws"
+	"github.com/anydotcloud/grm-generate/pkg/generate"
+	"github.com/anydotcloud/grm-generate/pkg/generate/aws"
+)
+
+const (
+	// compileModulePath is the Go module path of the temporary module that
+	// generated code is written to
+	compileModulePath = "example.com/generated"
+)
+
+// writeCompileModule writes a go.mod and go.sum to the supplied directory
+// that make it a Go module requiring the same dependencies as this
+// repository, so that the generated code's imports resolve to the
+// dependencies already in the module cache
+func writeCompileModule(t *testing.T, dir string) {
+	require := require.New(t)
+	root := filepath.Join("..", "..", "..")
+
+	goMod, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	require.Nil(err)
+	goMod = regexp.MustCompile(`(?m)^module .*$`).ReplaceAll(
+		goMod, []byte("module "+compileModulePath),
+	)
+	require.Nil(os.WriteFile(filepath.Join(dir, "go.mod"), goMod, 0644))
+
+	goSum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	require.Nil(err)
+	require.Nil(os.WriteFile(filepath.Join(dir, "go.sum"), goSum, 0644))
+}
+
+// getPackagePaths returns the sorted import paths of the packages in the
+// temporary module in the supplied directory
+func getPackagePaths(t *testing.T, dir string) []string {
+	found := map[string]bool{}
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() && strings.HasSuffix(path, ".go") {
+			found[filepath.Dir(path)] = true
+		}
+		return nil
+	})
+	require.Nil(t, err)
+	res := []string{}
+	for pkgDir := range found {
+		rel, err := filepath.Rel(dir, pkgDir)
+		require.Nil(t, err)
+		res = append(res, compileModulePath+"/"+filepath.ToSlash(rel))
+	}
+	sort.Strings(res)
+	return res
+}
+
+// packageDir returns the directory of the package with the supplied import
+// path in the temporary module in the supplied directory
+func packageDir(dir string, pkgPath string) string {
+	return filepath.Join(dir, filepath.FromSlash(
+		strings.TrimPrefix(pkgPath, compileModulePath+"/"),
+	))
+}
+
+// newExportImporter returns an importer that reads the export data of the
+// packages that the generated packages of the temporary module in the
+// supplied directory import, and of those packages' dependencies. The export
+// data is built by a single run of the go command.
+func newExportImporter(
+	t *testing.T,
+	fset *token.FileSet,
+	dir string,
+	pkgPaths []string,
+) types.Importer {
+	require := require.New(t)
+	imports := map[string]bool{}
+	for _, pkgPath := range pkgPaths {
+		pkgs, err := parser.ParseDir(
+			token.NewFileSet(), packageDir(dir, pkgPath), nil,
+			parser.ImportsOnly,
+		)
+		require.Nil(err)
+		for _, pkg := range pkgs {
+			for _, f := range pkg.Files {
+				for _, spec := range f.Imports {
+					imp := strings.Trim(spec.Path.Value, `"`)
+					if !strings.HasPrefix(imp, compileModulePath+"/") {
+						imports[imp] = true
+					}
+				}
+			}
+		}
+	}
+	args := []string{
+		"list", "-deps", "-export",
+		"-f", "{{ .ImportPath }}={{ .Export }}", "--",
+	}
+	args = append(args, lo.Keys(imports)...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	require.Nil(err, stderr.String())
+
+	// exports is a map, keyed by import path, of the path to the file
+	// containing the package's export data
+	exports := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), "=", 2)
+		if len(parts) == 2 && parts[1] != "" {
+			exports[parts[0]] = parts[1]
+		}
+	}
+	return importer.ForCompiler(
+		fset, "gc",
+		func(pkgPath string) (io.ReadCloser, error) {
+			exportPath, found := exports[pkgPath]
+			if !found {
+				return nil, fmt.Errorf("no export data for %s", pkgPath)
+			}
+			return os.Open(exportPath)
+		},
+	)
+}
+
+// moduleImporter type-checks the generated packages of the temporary module
+// and imports all other packages using a fallback importer
+type moduleImporter struct {
+	fset     *token.FileSet
+	dir      string
+	fallback types.Importer
+	// pkgs is a map, keyed by import path, of the type-checked generated
+	// packages
+	pkgs map[string]*types.Package
+	// errs is a map, keyed by import path, of the type errors found in each
+	// generated package
+	errs map[string][]error
+}
+
+func (i *moduleImporter) Import(pkgPath string) (*types.Package, error) {
+	if !strings.HasPrefix(pkgPath, compileModulePath+"/") {
+		return i.fallback.Import(pkgPath)
+	}
+	if pkg, found := i.pkgs[pkgPath]; found {
+		return pkg, nil
+	}
+	pkgs, err := parser.ParseDir(
+		i.fset, packageDir(i.dir, pkgPath), nil, parser.AllErrors,
+	)
+	if err != nil {
+		i.errs[pkgPath] = []error{err}
+		return nil, err
+	}
+	files := []*ast.File{}
+	for _, pkg := range pkgs {
+		fileNames := lo.Keys(pkg.Files)
+		sort.Strings(fileNames)
+		for _, fileName := range fileNames {
+			files = append(files, pkg.Files[fileName])
+		}
+	}
+	conf := types.Config{
+		Importer: i,
+		Error: func(err error) {
+			i.errs[pkgPath] = append(i.errs[pkgPath], err)
+		},
+	}
+	// Type errors are collected above. The returned package is complete
+	// enough for importing packages to be checked.
+	pkg, _ := conf.Check(pkgPath, i.fset, files, nil)
+	i.pkgs[pkgPath] = pkg
+	return pkg, nil
+}
+
+// TestGeneratedCodeCompiles renders every Go template for the resources of
+// each of the testdata services into a temporary Go module and type-checks
+// each generated package
+func TestGeneratedCodeCompiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping type-checking of generated code in short mode")
+	}
+	require := require.New(t)
+	ctx := context.TODO()
+
+	dir := t.TempDir()
+	writeCompileModule(t, dir)
+
+	for _, service := range services {
+		rds, err := discover.GetResourceDefinitionsForService(
+			ctx, service, apis[service], nil,
+		)
+		require.Nil(err)
+
+		files, err := generate.NewGoGenerator(
+			generate.WithPackageBase(compileModulePath),
+		).Generate(ctx, rds)
+		require.Nil(err)
+		sdkFiles, err := aws.NewGenerator(apis).Generate(ctx, rds)
+		require.Nil(err)
+		files = append(files, sdkFiles...)
+
+		require.Nil(generate.WriteFiles(dir, files))
+	}
+
+	pkgPaths := getPackagePaths(t, dir)
+	require.NotEmpty(pkgPaths)
+	fset := token.NewFileSet()
+	imp := &moduleImporter{
+		fset:     fset,
+		dir:      dir,
+		fallback: newExportImporter(t, fset, dir, pkgPaths),
+		pkgs:     map[string]*types.Package{},
+		errs:     map[string][]error{},
+	}
+	for _, pkgPath := range pkgPaths {
+		// Any syntax or type errors are collected by the importer
+		_, _ = imp.Import(pkgPath)
+	}
+	for _, pkgPath := range pkgPaths {
+		for _, err := range imp.errs[pkgPath] {
+			t.Errorf("%s: %s", pkgPath, err)
+		}
+	}
+}
+
+// accessorsTest is a test of the generated lambda Function resource that is
+// run inside the resource's generated package. Values set with SetAt, for
+// example decoded from JSON or YAML, need not have the Go type of the field,
+// and the typed getters and Delta must see them.
+const accessorsTest = `package v1
+
+import (
+	"testing"
+
+	"github.com/anydotcloud/grm/pkg/path/fieldpath"
+)
+
+func TestMixedAccessors(t *testing.T) {
+	a := New()
+	for path, v := range map[string]interface{}{
+		"Timeout": 30,
+		"VPCConfig": map[string]interface{}{
+			"SubnetIDs": []interface{}{"subnet-1"},
+		},
+		"FileSystemConfigs": []interface{}{
+			map[string]interface{}{"LocalMountPath": "/mnt/efs"},
+		},
+		"Layers": []interface{}{"layer-1"},
+	} {
+		if err := a.SetAt(fieldpath.FromString(path), v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if v := a.GetTimeout(); v == nil || *v != 30 {
+		t.Errorf("expected Timeout 30, got %v", v)
+	}
+	if v := a.GetVPCConfig(); v == nil || len(v.SubnetIDs) != 1 ||
+		v.SubnetIDs[0] != "subnet-1" {
+		t.Errorf("expected VPCConfig.SubnetIDs [subnet-1], got %v", v)
+	}
+	if v := a.GetFileSystemConfigs(); len(v) != 1 ||
+		v[0].LocalMountPath == nil || *v[0].LocalMountPath != "/mnt/efs" {
+		t.Errorf("expected FileSystemConfigs /mnt/efs, got %v", v)
+	}
+	if v := a.GetLayers(); len(v) != 1 || v[0] != "layer-1" {
+		t.Errorf("expected Layers [layer-1], got %v", v)
+	}
+
+	// A value that cannot be converted reads as unset
+	if err := a.SetAt(fieldpath.FromString("MemorySize"), "large"); err != nil {
+		t.Fatal(err)
+	}
+	if v := a.GetMemorySize(); v != nil {
+		t.Errorf("expected nil MemorySize, got %v", *v)
+	}
+
+	b := New()
+	mountPath := "/mnt/efs"
+	b.SetTimeout(30)
+	b.SetVPCConfig(&FunctionVPCConfig{SubnetIDs: []string{"subnet-1"}})
+	b.SetFileSystemConfigs([]*FunctionFileSystemConfigs{
+		{LocalMountPath: &mountPath},
+	})
+	b.SetLayers([]string{"layer-1"})
+	for _, path := range []string{
+		"Timeout", "VPCConfig", "FileSystemConfigs", "Layers",
+	} {
+		if a.Delta(b).DifferentAt(path) {
+			t.Errorf("expected no difference at %s", path)
+		}
+	}
+
+	b.SetTimeout(60)
+	b.SetVPCConfig(&FunctionVPCConfig{SubnetIDs: []string{"subnet-2"}})
+	for _, path := range []string{"Timeout", "VPCConfig.SubnetIDs"} {
+		if !a.Delta(b).DifferentAt(path) {
+			t.Errorf("expected a difference at %s", path)
+		}
+	}
+}
+`
+
+// TestGeneratedAccessors renders the Go templates for the lambda testdata
+// service into a temporary Go module and runs accessorsTest in the lambda
+// Function resource's package
+func TestGeneratedAccessors(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping running generated code in short mode")
+	}
+	require := require.New(t)
+	ctx := context.TODO()
+
+	dir := t.TempDir()
+	writeCompileModule(t, dir)
+
+	rds, err := discover.GetResourceDefinitionsForService(
+		ctx, "lambda", apis["lambda"], nil,
+	)
+	require.Nil(err)
+	files, err := generate.NewGoGenerator(
+		generate.WithPackageBase(compileModulePath),
+	).Generate(ctx, rds)
+	require.Nil(err)
+	files = append(files, &generate.File{
+		Path:     "lambda/function/v1/accessors_test.go",
+		Contents: []byte(accessorsTest),
+	})
+	require.Nil(generate.WriteFiles(dir, files))
+
+	cmd := exec.Command("go", "test", "./lambda/function/v1/")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.Nil(err, string(out))
+}
@@ -0,0 +1,71 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package aws
+
+import (
+	"github.com/anydotcloud/grm-generate/pkg/config"
+	"github.com/anydotcloud/grm-generate/pkg/generate"
+)
+
+type option struct {
+	cfg          *config.Config
+	templateDirs []string
+	apiVersion   string
+}
+
+// WithConfig uses the supplied Config to map API operations and renamed
+// fields to resources
+func WithConfig(cfg *config.Config) option {
+	return option{
+		cfg: cfg,
+	}
+}
+
+// WithTemplateDirs instructs the generator to load Go templates from the
+// supplied directories in addition to the default templates. Templates in
+// later directories override or extend the templates with the same path in
+// earlier directories and in the default templates.
+func WithTemplateDirs(paths ...string) option {
+	return option{
+		templateDirs: paths,
+	}
+}
+
+// WithAPIVersion instructs the generator which Go package name is used for
+// the generated resource types, e.g. "v1"
+func WithAPIVersion(apiVersion string) option {
+	return option{
+		apiVersion: apiVersion,
+	}
+}
+
+// mergeOptions merges any supplied option values with any defaults and returns
+// a single option
+func mergeOptions(opts []option) option {
+	res := option{}
+	for _, opt := range opts {
+		if opt.cfg != nil {
+			res.cfg = opt.cfg
+		}
+		if len(opt.templateDirs) > 0 {
+			res.templateDirs = append(res.templateDirs, opt.templateDirs...)
+		}
+		if opt.apiVersion != "" {
+			res.apiVersion = opt.apiVersion
+		}
+	}
+	// now process the defaults...
+	if res.apiVersion == "" {
+		res.apiVersion = generate.DefaultAPIVersion
+	}
+	return res
+}
@@ -0,0 +1,423 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package aws
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/anydotcloud/grm/pkg/names"
+	"github.com/anydotcloud/grm/pkg/path/fieldpath"
+	"github.com/anydotcloud/grm/pkg/types/resource/schema"
+	awssdkmodel "github.com/aws/aws-sdk-go/private/model/api"
+
+	discover "github.com/anydotcloud/grm-generate/pkg/discover/aws"
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+var (
+	// sdkOpTypes contains the types of the API operations that are called
+	// for a resource, in the order their functions are generated
+	sdkOpTypes = []discover.OpType{
+		discover.OpTypeCreate,
+		discover.OpTypeGet,
+		discover.OpTypeUpdate,
+		discover.OpTypeDelete,
+	}
+)
+
+// sdkData is the data passed to the sdk.go.tpl template
+type sdkData struct {
+	// Version is the name of the Go package containing the resource type
+	Version string
+	Kind    model.Kind
+	// SDKPackage is the import path of the aws-sdk-go service package
+	SDKPackage string
+	// Operations contains the resource's API operations
+	Operations []*sdkOperation
+	// ImportsAWS is true if any value is converted using the aws-sdk-go aws
+	// package
+	ImportsAWS bool
+	// numVars is the number of Go variables declared so far, used to give
+	// each variable a unique name
+	numVars int
+}
+
+// sdkOperation describes the input and output of a single API operation
+type sdkOperation struct {
+	// Type is the Go identifier of the operation type, e.g. "Create", used as
+	// part of the generated function names
+	Type string
+	// Name is the name of the API operation, e.g. "CreateRepository"
+	Name string
+	// InputType is the name of the operation's input struct type
+	InputType string
+	// OutputType is the name of the operation's output struct type
+	OutputType string
+	// OutputWrapper is the name of the output member that wraps the members
+	// copied into the resource, or the empty string if the members are
+	// copied directly from the output
+	OutputWrapper string
+	// Inputs contains the copy of each resource field into an input member
+	Inputs []*sdkField
+	// Outputs contains the copy of each output member into a resource field
+	Outputs []*sdkField
+}
+
+// sdkField describes the copy of a value between a resource field and an
+// SDK shape member
+type sdkField struct {
+	// Source is the Go expression of the value that is copied
+	Source string
+	// Var is the Go variable that the source value is bound to
+	Var string
+	// Target is the Go expression that is assigned to or, if IsSetter is
+	// true, the setter method that is called with the copied value
+	Target   string
+	IsSetter bool
+	// Value is the Go expression of the copied value
+	Value string
+	// Copy is how the value is copied: "value", "struct", "list" or "map".
+	// Values are assigned after any conversion. Structs, and the struct
+	// elements of lists and maps, are copied member by member.
+	Copy string
+	// Type is the Go type of the target struct or struct element
+	Type string
+	// Result is the Go variable holding the copied struct, list or map
+	Result string
+	// Elem is the Go variable bound to each list element or map value
+	Elem string
+	// Key is the Go variable bound to each map key
+	Key string
+	// StructResult is the Go variable holding the copied struct or struct
+	// element
+	StructResult string
+	// Members contains the copy of each member of a struct or struct element
+	Members []*sdkField
+}
+
+// sdkScalar describes how values of an aws-sdk-go scalar shape type are
+// converted
+type sdkScalar struct {
+	// FieldType is the type of the resource field holding the value
+	FieldType schema.FieldType
+	// Convert is the name of the aws-sdk-go aws package's conversion
+	// functions for the type, e.g. "String" for StringSlice and
+	// StringValueMap
+	Convert string
+}
+
+// sdkScalars is a map, keyed by aws-sdk-go Shape.Type, of the scalar shape
+// types that can be copied
+var sdkScalars = map[string]sdkScalar{
+	"string":    {schema.FieldTypeString, "String"},
+	"character": {schema.FieldTypeString, "String"},
+	"boolean":   {schema.FieldTypeBool, "Bool"},
+	"byte":      {schema.FieldTypeInt, "Int64"},
+	"short":     {schema.FieldTypeInt, "Int64"},
+	"integer":   {schema.FieldTypeInt, "Int64"},
+	"long":      {schema.FieldTypeInt, "Int64"},
+	"float":     {schema.FieldTypeFloat, "Float64"},
+	"double":    {schema.FieldTypeFloat, "Float64"},
+	"timestamp": {schema.FieldTypeTime, "Time"},
+}
+
+// newSDKData returns the data passed to the sdk.go.tpl template for the
+// supplied resource and its API operations
+func newSDKData(
+	version string,
+	rd *model.ResourceDefinition,
+	ops map[discover.OpType]*awssdkmodel.Operation,
+) *sdkData {
+	res := &sdkData{
+		Version:    version,
+		Kind:       rd.Kind,
+		SDKPackage: path.Join(sdkPackageBase, rd.Kind.Service),
+	}
+	for _, opType := range sdkOpTypes {
+		op, found := ops[opType]
+		if !found || op.InputRef.Shape == nil || op.OutputRef.Shape == nil {
+			continue
+		}
+		res.Operations = append(
+			res.Operations, res.newSDKOperation(rd, opType, op),
+		)
+	}
+	return res
+}
+
+// newSDKOperation returns the copies between the supplied resource's fields
+// and the members of the input and output shapes of the supplied operation.
+// Members that do not correspond to a resource field are not copied.
+func (d *sdkData) newSDKOperation(
+	rd *model.ResourceDefinition,
+	opType discover.OpType,
+	op *awssdkmodel.Operation,
+) *sdkOperation {
+	inputShape := op.InputRef.Shape
+	outputShape := op.OutputRef.Shape
+	res := &sdkOperation{
+		Type:       names.New(opType.String()).Camel,
+		Name:       op.ExportedName,
+		InputType:  inputShape.ShapeName,
+		OutputType: outputShape.ShapeName,
+	}
+	for _, memberName := range inputShape.MemberNames() {
+		field := getResourceField(rd, memberName)
+		if field == nil {
+			continue
+		}
+		getter := "r.Get" + names.New(field.Path.Back()).Camel + "()"
+		f := d.newSDKField(
+			rd, getter, "res."+memberName, false, true,
+			inputShape.MemberRefs[memberName].Shape, field.Path,
+			field.Definition,
+		)
+		if f != nil {
+			res.Inputs = append(res.Inputs, f)
+		}
+	}
+
+	// Outputs commonly wrap the resource's members in a single structure
+	// member, e.g. CreateRepositoryOutput.Repository
+	source := "out"
+	if wrapper := getOutputWrapper(rd, outputShape); wrapper != "" {
+		res.OutputWrapper = wrapper
+		source += "." + wrapper
+		outputShape = outputShape.MemberRefs[wrapper].Shape
+	}
+	for _, memberName := range outputShape.MemberNames() {
+		field := getResourceField(rd, memberName)
+		if field == nil {
+			continue
+		}
+		setter := "r.Set" + names.New(field.Path.Back()).Camel
+		f := d.newSDKField(
+			rd, source+"."+memberName, setter, true, false,
+			outputShape.MemberRefs[memberName].Shape, field.Path,
+			field.Definition,
+		)
+		if f != nil {
+			res.Outputs = append(res.Outputs, f)
+		}
+	}
+	return res
+}
+
+// newSDKField returns the copy of the value of the supplied Go expression to
+// the supplied target. When toSDK is true, the value of a resource field is
+// copied into a member of the supplied shape, otherwise the value of a
+// member of the supplied shape is copied into a resource field. Returns nil
+// if the shape and field definition have types that cannot be copied
+// between each other.
+func (d *sdkData) newSDKField(
+	rd *model.ResourceDefinition,
+	source string,
+	target string,
+	isSetter bool,
+	toSDK bool,
+	shape *awssdkmodel.Shape,
+	fieldPath *fieldpath.Path,
+	def *model.FieldDefinition,
+) *sdkField {
+	res := &sdkField{
+		Source:   source,
+		Var:      d.newVar("v"),
+		Target:   target,
+		IsSetter: isSetter,
+	}
+	if scalar, found := sdkScalars[shape.Type]; found {
+		if def.Type != scalar.FieldType {
+			return nil
+		}
+		res.Copy = "value"
+		res.Value = res.Var
+		if isSetter {
+			// Top-level setters take the value rather than a pointer
+			res.Value = "*" + res.Var
+		}
+		return res
+	}
+	// elemShape is the shape of a list's elements or a map's values
+	var elemShape *awssdkmodel.Shape
+	var elemType schema.FieldType
+	switch shape.Type {
+	case "structure":
+		if def.Type != schema.FieldTypeStruct {
+			return nil
+		}
+		res.Copy = "struct"
+		res.StructResult = d.newVar("f")
+		res.Value = res.StructResult
+		res.Type = d.structType(rd, toSDK, shape, fieldPath)
+		res.Members = d.newSDKMembers(
+			rd, res.Var, res.StructResult, toSDK, shape, fieldPath, def,
+		)
+		return res
+	case "list":
+		if def.Type != schema.FieldTypeList {
+			return nil
+		}
+		res.Copy = "list"
+		elemShape = shape.MemberRef.Shape
+		elemType = def.ElementType
+	case "map":
+		if def.Type != schema.FieldTypeMap ||
+			def.KeyType != schema.FieldTypeString {
+			return nil
+		}
+		res.Copy = "map"
+		elemShape = shape.ValueRef.Shape
+		elemType = def.ValueType
+	default:
+		return nil
+	}
+	if scalar, found := sdkScalars[elemShape.Type]; found {
+		if elemType != scalar.FieldType {
+			return nil
+		}
+		convert := scalar.Convert
+		if !toSDK {
+			convert += "Value"
+		}
+		if res.Copy == "list" {
+			convert += "Slice"
+		} else {
+			convert += "Map"
+		}
+		d.ImportsAWS = true
+		res.Copy = "value"
+		res.Value = fmt.Sprintf("aws.%s(%s)", convert, res.Var)
+		return res
+	}
+	if elemShape.Type != "structure" || elemType != schema.FieldTypeStruct {
+		return nil
+	}
+	res.Result = d.newVar("f")
+	res.Value = res.Result
+	if res.Copy == "map" {
+		res.Key = d.newVar("k")
+	}
+	res.Elem = d.newVar("e")
+	res.StructResult = d.newVar("f")
+	res.Type = d.structType(rd, toSDK, elemShape, fieldPath)
+	res.Members = d.newSDKMembers(
+		rd, res.Elem, res.StructResult, toSDK, elemShape, fieldPath, def,
+	)
+	return res
+}
+
+// newSDKMembers returns the copies of the members of a struct, or of the
+// struct elements of a list or map, from the supplied source variable to
+// the supplied target variable. Members that have been renamed are copied
+// to or from the resource struct member with the new name.
+func (d *sdkData) newSDKMembers(
+	rd *model.ResourceDefinition,
+	source string,
+	target string,
+	toSDK bool,
+	shape *awssdkmodel.Shape,
+	containerPath *fieldpath.Path,
+	containerDef *model.FieldDefinition,
+) []*sdkField {
+	res := []*sdkField{}
+	for _, memberName := range shape.MemberNames() {
+		memberPath := containerPath.Copy()
+		memberPath.PushBack(names.New(memberName).Camel)
+		if _, repath := rd.Config.GetFieldConfig(memberPath); repath != nil {
+			memberPath = repath
+		}
+		defName := names.New(memberPath.Back()).Camel
+		def, found := containerDef.MemberFieldDefinitions[defName]
+		if !found {
+			// the member field is ignored...
+			continue
+		}
+		fieldName := names.New(defName).Camel
+		memberSource := source + "." + memberName
+		memberTarget := target + "." + fieldName
+		if toSDK {
+			memberSource = source + "." + fieldName
+			memberTarget = target + "." + memberName
+		}
+		f := d.newSDKField(
+			rd, memberSource, memberTarget, false, toSDK,
+			shape.MemberRefs[memberName].Shape, memberPath, def,
+		)
+		if f != nil {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+// structType returns the Go type of the struct that is copied to. When toSDK
+// is true, this is the aws-sdk-go type of the supplied shape. Otherwise it is
+// the resource struct type generated for the field at the supplied field
+// path.
+func (d *sdkData) structType(
+	rd *model.ResourceDefinition,
+	toSDK bool,
+	shape *awssdkmodel.Shape,
+	fieldPath *fieldpath.Path,
+) string {
+	if toSDK {
+		return "svcsdk." + shape.ShapeName
+	}
+	res := rd.Kind.Name
+	for x := 0; x < fieldPath.Size(); x++ {
+		res += names.New(fieldPath.At(x)).Camel
+	}
+	return res
+}
+
+// newVar returns a new, unique Go variable name with the supplied prefix
+func (d *sdkData) newVar(prefix string) string {
+	d.numVars++
+	return fmt.Sprintf("%s%d", prefix, d.numVars)
+}
+
+// getResourceField returns the top-level resource field that the input or
+// output member with the supplied name corresponds to, taking any field
+// renames into account, or nil if there is no such field
+func getResourceField(
+	rd *model.ResourceDefinition,
+	memberName string,
+) *model.Field {
+	path := fieldpath.FromString(memberName)
+	if _, repath := rd.Config.GetFieldConfig(path); repath != nil {
+		path = repath
+	}
+	return rd.GetField(path)
+}
+
+// getOutputWrapper returns the name of the supplied output shape's only
+// member if that member is a structure that does not itself correspond to a
+// resource field, otherwise the empty string
+func getOutputWrapper(
+	rd *model.ResourceDefinition,
+	outputShape *awssdkmodel.Shape,
+) string {
+	memberNames := outputShape.MemberNames()
+	if len(memberNames) != 1 {
+		return ""
+	}
+	memberName := memberNames[0]
+	shape := outputShape.MemberRefs[memberName].Shape
+	if shape == nil || shape.Type != "structure" {
+		return ""
+	}
+	if getResourceField(rd, memberName) != nil {
+		return ""
+	}
+	return memberName
+}
@@ -0,0 +1,64 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generate
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/anydotcloud/grm-generate/pkg/config"
+)
+
+// boilerplateHeader returns the Go comment containing the license header
+// described by the supplied BoilerplateConfig, or the empty string if the
+// config is nil. The header's text is read inline or from a file, its
+// placeholders are replaced and any line that is not already a Go comment is
+// commented. Returns an error if the text contains the year placeholder and no
+// year is configured, so that the output does not depend on the date it was
+// generated on.
+func boilerplateHeader(cfg *config.BoilerplateConfig) (string, error) {
+	if cfg == nil {
+		return "", nil
+	}
+	text := cfg.Text
+	if cfg.Path != "" {
+		b, err := os.ReadFile(cfg.Path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read boilerplate file: %v", err)
+		}
+		text = string(b)
+	}
+	if cfg.Year == 0 &&
+		strings.Contains(text, config.BoilerplateYearPlaceholder) {
+		return "", fmt.Errorf(
+			"boilerplate: year is required when the text contains %s",
+			config.BoilerplateYearPlaceholder,
+		)
+	}
+	text = strings.NewReplacer(
+		config.BoilerplateYearPlaceholder, strconv.Itoa(cfg.Year),
+		config.BoilerplateVendorPlaceholder, cfg.Vendor,
+	).Replace(strings.TrimRight(text, "\n"))
+	lines := strings.Split(text, "\n")
+	for x, line := range lines {
+		switch {
+		case strings.HasPrefix(strings.TrimSpace(line), "//"):
+		case strings.TrimSpace(line) == "":
+			lines[x] = "//"
+		default:
+			lines[x] = "// " + line
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
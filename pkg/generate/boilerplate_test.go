@@ -0,0 +1,177 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generate_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anydotcloud/grm-generate/pkg/config"
+	"github.com/anydotcloud/grm-generate/pkg/generate"
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+func TestBoilerplate(t *testing.T) {
+	dir := t.TempDir()
+	headerPath := filepath.Join(dir, "boilerplate.txt")
+	require.Nil(t, os.WriteFile(
+		headerPath,
+		[]byte("// Copyright ${YEAR} ${VENDOR}\n//\n// Proprietary\n"),
+		0o644,
+	))
+
+	tests := []struct {
+		name   string
+		target string
+		yaml   string
+		// expHeader is the expected beginning of every file, before the
+		// generated code marker
+		expHeader string
+	}{
+		{
+			name:      "default header",
+			target:    generate.TargetGo,
+			expHeader: "// Licensed under the Apache License, Version 2.0",
+		},
+		{
+			name:   "inline text with placeholders",
+			target: generate.TargetGo,
+			yaml: `
+boilerplate:
+  text: |
+    Copyright ${YEAR} ${VENDOR}
+
+    All rights reserved.
+  year: 2021
+  vendor: Example Corp
+`,
+			expHeader: "// Copyright 2021 Example Corp\n//\n// All rights reserved.",
+		},
+		{
+			name:   "file path",
+			target: generate.TargetGo,
+			yaml: `
+boilerplate:
+  path: ` + headerPath + `
+  year: 2022
+  vendor: Example Corp
+`,
+			expHeader: "// Copyright 2022 Example Corp\n//\n// Proprietary",
+		},
+		{
+			name:   "protocol buffers",
+			target: generate.TargetProto,
+			yaml: `
+boilerplate:
+  text: Copyright ${YEAR} ${VENDOR}
+  year: 2023
+  vendor: Example Corp
+`,
+			expHeader: "// Copyright 2023 Example Corp",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			gen, err := generate.New(
+				tt.target,
+				generate.WithConfig(config.New(config.WithYAML(tt.yaml))),
+				generate.WithOutputPath(t.TempDir()),
+			)
+			require.Nil(err)
+			files, err := gen.Generate(
+				context.TODO(),
+				[]*model.ResourceDefinition{newRepository("Name")},
+			)
+			require.Nil(err)
+			for _, f := range files {
+				if !strings.HasSuffix(f.Path, ".go") &&
+					!strings.HasSuffix(f.Path, ".proto") {
+					continue
+				}
+				contents := string(f.Contents)
+				assert.True(
+					t, strings.HasPrefix(contents, tt.expHeader), f.Path,
+				)
+				// The generated code marker is always written after the
+				// header
+				assert.Contains(
+					t, contents,
+					"\n\n// Code generated by grm-generate. DO NOT EDIT.\n", f.Path,
+				)
+			}
+		})
+	}
+}
+
+func TestBoilerplateMissingFile(t *testing.T) {
+	gen := generate.NewGoGenerator(
+		generate.WithConfig(config.New(config.WithYAML(`
+boilerplate:
+  path: ` + filepath.Join(t.TempDir(), "missing.txt") + `
+`))),
+	)
+	_, err := gen.Generate(
+		context.TODO(),
+		[]*model.ResourceDefinition{newRepository("Name")},
+	)
+	require.NotNil(t, err)
+}
+
+func TestBoilerplateMissingYear(t *testing.T) {
+	tests := []struct {
+		name   string
+		yaml   string
+		expErr bool
+	}{
+		{
+			name: "year placeholder without year",
+			yaml: `
+boilerplate:
+  text: Copyright ${YEAR} ${VENDOR}
+  vendor: Example Corp
+`,
+			expErr: true,
+		},
+		{
+			name: "no year placeholder",
+			yaml: `
+boilerplate:
+  text: Copyright ${VENDOR}
+  vendor: Example Corp
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := generate.NewGoGenerator(
+				generate.WithConfig(config.New(config.WithYAML(tt.yaml))),
+			)
+			_, err := gen.Generate(
+				context.TODO(),
+				[]*model.ResourceDefinition{newRepository("Name")},
+			)
+			if tt.expErr {
+				require.NotNil(t, err)
+				assert.Contains(t, err.Error(), "year is required")
+			} else {
+				require.Nil(t, err)
+			}
+		})
+	}
+}
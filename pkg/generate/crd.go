@@ -0,0 +1,231 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+// crd is a Kubernetes apiextensions.k8s.io/v1 CustomResourceDefinition
+type crd struct {
+	APIVersion string      `json:"apiVersion"`
+	Kind       string      `json:"kind"`
+	Metadata   crdMetadata `json:"metadata"`
+	Spec       crdSpec     `json:"spec"`
+}
+
+type crdMetadata struct {
+	Name string `json:"name"`
+}
+
+type crdSpec struct {
+	Group    string       `json:"group"`
+	Names    crdNames     `json:"names"`
+	Scope    string       `json:"scope"`
+	Versions []crdVersion `json:"versions"`
+}
+
+type crdNames struct {
+	Kind     string `json:"kind"`
+	ListKind string `json:"listKind"`
+	Plural   string `json:"plural"`
+	Singular string `json:"singular"`
+}
+
+type crdVersion struct {
+	Name         string          `json:"name"`
+	Served       bool            `json:"served"`
+	Storage      bool            `json:"storage"`
+	Schema       crdSchema       `json:"schema"`
+	Subresources crdSubresources `json:"subresources"`
+}
+
+type crdSchema struct {
+	OpenAPIV3Schema *OpenAPISchema `json:"openAPIV3Schema"`
+}
+
+type crdSubresources struct {
+	Status struct{} `json:"status"`
+}
+
+// crdGenerator writes a Kubernetes CustomResourceDefinition manifest for each
+// resource. It implements the `Generator` interface.
+type crdGenerator struct {
+	opts option
+}
+
+func (g *crdGenerator) Generate(
+	ctx context.Context,
+	rds []*model.ResourceDefinition,
+) ([]*File, error) {
+	res := []*File{}
+	for _, rd := range rds {
+		c := g.newCRD(rd)
+		b, err := yaml.Marshal(c)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to marshal CustomResourceDefinition for %s: %s",
+				rd.Kind.Name, err,
+			)
+		}
+		res = append(res, &File{
+			Path:     c.Metadata.Name + ".yaml",
+			Contents: b,
+		})
+	}
+	return res, nil
+}
+
+// newCRD returns the CustomResourceDefinition for the supplied resource. The
+// resource's read-only fields are placed in the status and all other fields
+// are placed in the spec.
+func (g *crdGenerator) newCRD(rd *model.ResourceDefinition) *crd {
+	group := strings.ToLower(rd.Kind.Service + "." + rd.Kind.CloudProvider)
+	plural := strings.ToLower(rd.Kind.PluralName)
+	specMembers := map[string]*model.FieldDefinition{}
+	statusMembers := map[string]*model.FieldDefinition{}
+	for _, fp := range rd.GetFieldPaths() {
+		if fp.Size() != 1 {
+			continue
+		}
+		def := rd.GetField(fp).Definition
+		if def.IsReadOnly {
+			statusMembers[fp.Back()] = def
+		} else {
+			specMembers[fp.Back()] = def
+		}
+	}
+	spec := &OpenAPISchema{}
+	setOpenAPIObject(spec, specMembers)
+	for name, def := range specMembers {
+		setCRDImmutable(spec.Properties[name], def, name)
+	}
+	setCRDStructural(spec)
+	status := &OpenAPISchema{}
+	setOpenAPIObject(status, statusMembers)
+	// The status is only written by the controller so no fields are required
+	status.Required = nil
+	setCRDStructural(status)
+
+	return &crd{
+		APIVersion: "apiextensions.k8s.io/v1",
+		Kind:       "CustomResourceDefinition",
+		Metadata: crdMetadata{
+			Name: plural + "." + group,
+		},
+		Spec: crdSpec{
+			Group: group,
+			Names: crdNames{
+				Kind:     rd.Kind.Name,
+				ListKind: rd.Kind.Name + "List",
+				Plural:   plural,
+				Singular: strings.ToLower(rd.Kind.Name),
+			},
+			Scope: "Namespaced",
+			Versions: []crdVersion{
+				{
+					Name:    g.opts.apiVersion,
+					Served:  true,
+					Storage: true,
+					Schema: crdSchema{
+						OpenAPIV3Schema: &OpenAPISchema{
+							Description: resourceDescription(rd.Kind),
+							Type:        "object",
+							Properties: map[string]*OpenAPISchema{
+								"apiVersion": {Type: "string"},
+								"kind":       {Type: "string"},
+								"metadata":   {Type: "object"},
+								"spec":       spec,
+								"status":     status,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// setCRDImmutable adds a validation rule preventing changes to the value of
+// each immutable field, and immutable member field, of the supplied field
+// definition to the supplied schema. Member fields of list elements are
+// skipped.
+func setCRDImmutable(
+	s *OpenAPISchema,
+	def *model.FieldDefinition,
+	path string,
+) {
+	if def.IsImmutable {
+		s.XKubernetesValidations = append(
+			s.XKubernetesValidations, &ValidationRule{
+				Rule:    "self == oldSelf",
+				Message: path + " is immutable",
+			},
+		)
+	}
+	// Kubernetes only allows transition rules where it can correlate the old
+	// and new values. The elements of a list that is not a map list cannot be
+	// correlated, so immutable member fields of a list of structs are not
+	// enforced. Member fields of a map of structs are described by the map
+	// value schema.
+	if s.Items != nil {
+		return
+	}
+	obj := s
+	if s.AdditionalProperties != nil {
+		obj = s.AdditionalProperties
+	}
+	for name, memberDef := range def.MemberFieldDefinitions {
+		if memberSchema, found := obj.Properties[name]; found {
+			setCRDImmutable(memberSchema, memberDef, path+"."+name)
+		}
+	}
+}
+
+// setCRDStructural modifies the supplied schema and its nested schemas to be
+// a structural schema as required by Kubernetes: every schema has a type or
+// preserves unknown fields and every array has an items schema. Read-only
+// markers are removed since the status conveys which fields are read-only.
+func setCRDStructural(s *OpenAPISchema) {
+	s.ReadOnly = false
+	if s.Type == "" {
+		s.XKubernetesPreserveUnknownFields = true
+	}
+	if s.Type == "array" && s.Items == nil {
+		s.Items = &OpenAPISchema{XKubernetesPreserveUnknownFields: true}
+	}
+	if s.Items != nil {
+		setCRDStructural(s.Items)
+	}
+	if s.AdditionalProperties != nil {
+		setCRDStructural(s.AdditionalProperties)
+	}
+	for _, member := range s.Properties {
+		setCRDStructural(member)
+	}
+}
+
+// NewCRDGenerator returns a new Generator that writes a Kubernetes
+// CustomResourceDefinition manifest for each resource
+func NewCRDGenerator(
+	opts ...option,
+) Generator {
+	return &crdGenerator{
+		opts: mergeOptions(opts),
+	}
+}
@@ -0,0 +1,176 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/anydotcloud/grm/pkg/path/fieldpath"
+	"github.com/anydotcloud/grm/pkg/types/resource/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anydotcloud/grm-generate/pkg/generate"
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+func TestCRDGenerator(t *testing.T) {
+	require := require.New(t)
+
+	rd := newRepository("Name")
+	rd.GetField(fieldpath.FromString("Name")).Definition.IsImmutable = true
+	encDef := &model.FieldDefinition{
+		Type: schema.FieldTypeStruct,
+		MemberFieldDefinitions: map[string]*model.FieldDefinition{
+			"KMSKey": {
+				Type:        schema.FieldTypeString,
+				IsImmutable: true,
+			},
+		},
+	}
+	rd.AddField(model.NewField(
+		fieldpath.FromString("EncryptionConfiguration"), nil, encDef,
+	))
+	rd.AddField(model.NewField(
+		fieldpath.FromString("EncryptionConfiguration.KMSKey"), nil,
+		encDef.MemberFieldDefinitions["KMSKey"],
+	))
+	// Transition rules are allowed for the member fields of map values but
+	// not of list elements
+	tagsDef := &model.FieldDefinition{
+		Type:        schema.FieldTypeList,
+		ElementType: schema.FieldTypeStruct,
+		MemberFieldDefinitions: map[string]*model.FieldDefinition{
+			"Key": {
+				Type:        schema.FieldTypeString,
+				IsImmutable: true,
+			},
+		},
+	}
+	rd.AddField(model.NewField(
+		fieldpath.FromString("Tags"), nil, tagsDef,
+	))
+	rd.AddField(model.NewField(
+		fieldpath.FromString("Tags.Key"), nil,
+		tagsDef.MemberFieldDefinitions["Key"],
+	))
+	rulesDef := &model.FieldDefinition{
+		Type:      schema.FieldTypeMap,
+		KeyType:   schema.FieldTypeString,
+		ValueType: schema.FieldTypeStruct,
+		MemberFieldDefinitions: map[string]*model.FieldDefinition{
+			"Prefix": {
+				Type:        schema.FieldTypeString,
+				IsImmutable: true,
+			},
+		},
+	}
+	rd.AddField(model.NewField(
+		fieldpath.FromString("Rules"), nil, rulesDef,
+	))
+	rd.AddField(model.NewField(
+		fieldpath.FromString("Rules.Prefix"), nil,
+		rulesDef.MemberFieldDefinitions["Prefix"],
+	))
+	rd.AddField(model.NewField(
+		fieldpath.FromString("Arn"), nil,
+		&model.FieldDefinition{
+			Type:       schema.FieldTypeString,
+			IsReadOnly: true,
+			IsRequired: true,
+		},
+	))
+
+	gen, err := generate.New(
+		generate.TargetCRD, generate.WithAPIVersion("v1alpha1"),
+	)
+	require.Nil(err)
+	files, err := gen.Generate(
+		context.TODO(), []*model.ResourceDefinition{rd},
+	)
+	require.Nil(err)
+	require.Len(files, 1)
+	require.Equal("repositories.ecr.aws.yaml", files[0].Path)
+
+	expect := `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: repositories.ecr.aws
+spec:
+  group: ecr.aws
+  names:
+    kind: Repository
+    listKind: RepositoryList
+    plural: repositories
+    singular: repository
+  scope: Namespaced
+  versions:
+  - name: v1alpha1
+    schema:
+      openAPIV3Schema:
+        description: Repository is a resource of the AWS ecr service
+        properties:
+          apiVersion:
+            type: string
+          kind:
+            type: string
+          metadata:
+            type: object
+          spec:
+            properties:
+              EncryptionConfiguration:
+                properties:
+                  KMSKey:
+                    type: string
+                    x-kubernetes-validations:
+                    - message: EncryptionConfiguration.KMSKey is immutable
+                      rule: self == oldSelf
+                type: object
+              Name:
+                type: string
+                x-kubernetes-validations:
+                - message: Name is immutable
+                  rule: self == oldSelf
+              Rules:
+                additionalProperties:
+                  properties:
+                    Prefix:
+                      type: string
+                      x-kubernetes-validations:
+                      - message: Rules.Prefix is immutable
+                        rule: self == oldSelf
+                  type: object
+                type: object
+              Tags:
+                items:
+                  properties:
+                    Key:
+                      type: string
+                  type: object
+                type: array
+            required:
+            - Name
+            type: object
+          status:
+            properties:
+              ARN:
+                type: string
+            type: object
+        type: object
+    served: true
+    storage: true
+    subresources:
+      status: {}
+`
+	assert.Equal(t, expect, string(files[0].Contents))
+}
@@ -0,0 +1,108 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"go/format"
+	"go/scanner"
+	"strings"
+
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+const (
+	// formatContextLines is the number of lines of rendered source shown
+	// before and after each line that could not be formatted
+	formatContextLines = 2
+)
+
+// FormatError describes Go source rendered from a template that could not be
+// formatted, usually because the template renders invalid Go
+type FormatError struct {
+	// Template is the name of the template the source was rendered from
+	Template string
+	// Kind is the type of the resource the source was rendered for
+	Kind model.Kind
+	// Path is the path of the generated file
+	Path string
+	// Source is the unformatted, rendered source
+	Source []byte
+	// Err is the error returned when formatting the source
+	Err error
+}
+
+// Error returns the formatting errors along with the offending lines of the
+// rendered source and the lines surrounding them
+func (e *FormatError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(
+		&b, "failed to format Go rendered from template %s for resource "+
+			"%s of service %s (%s)",
+		e.Template, e.Kind.Name, e.Kind.Service, e.Path,
+	)
+	var errList scanner.ErrorList
+	if !errors.As(e.Err, &errList) {
+		fmt.Fprintf(&b, ": %s", e.Err)
+		return b.String()
+	}
+	lines := strings.Split(string(e.Source), "\n")
+	for _, err := range errList {
+		fmt.Fprintf(
+			&b, "\n%d:%d: %s\n", err.Pos.Line, err.Pos.Column, err.Msg,
+		)
+		start := err.Pos.Line - formatContextLines
+		if start < 1 {
+			start = 1
+		}
+		end := err.Pos.Line + formatContextLines
+		if end > len(lines) {
+			end = len(lines)
+		}
+		for line := start; line <= end; line++ {
+			marker := " "
+			if line == err.Pos.Line {
+				marker = ">"
+			}
+			fmt.Fprintf(&b, "%s %5d | %s\n", marker, line, lines[line-1])
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Unwrap returns the error returned when formatting the source
+func (e *FormatError) Unwrap() error {
+	return e.Err
+}
+
+// formatGo returns the gofmt-formatted Go source rendered from the named
+// template for the supplied resource, or a FormatError if the source cannot
+// be formatted
+func formatGo(
+	src []byte,
+	tplName string,
+	kind model.Kind,
+	filePath string,
+) ([]byte, error) {
+	res, err := format.Source(src)
+	if err != nil {
+		return nil, &FormatError{
+			Template: tplName,
+			Kind:     kind,
+			Path:     filePath,
+			Source:   src,
+			Err:      err,
+		}
+	}
+	return res, nil
+}
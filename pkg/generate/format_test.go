@@ -0,0 +1,108 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anydotcloud/grm-generate/pkg/generate"
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+func TestGoGeneratorFormatting(t *testing.T) {
+	tests := []struct {
+		name string
+		// kindTemplate is the contents of the template overriding
+		// resource/schema/kind.go.tpl
+		kindTemplate string
+		// expContents is the expected contents of the kind.go file, after
+		// the generated code marker
+		expContents string
+		// expErrs contains substrings of the expected error message
+		expErrs []string
+	}{
+		{
+			name: "unformatted source is formatted",
+			kindTemplate: `{{- template "boilerplate" }}
+package schema
+const (
+    A = 1
+    LongName = "{{ .Name }}"
+)
+`,
+			expContents: `
+package schema
+
+const (
+	A        = 1
+	LongName = "Repository"
+)
+`,
+		},
+		{
+			name: "invalid source reports template, kind and lines",
+			kindTemplate: `{{- template "boilerplate" }}
+
+package schema
+
+func {{ .Name }}( {
+	return
+}
+`,
+			expErrs: []string{
+				"template resource/schema/kind.go.tpl",
+				"resource Repository of service ecr",
+				"ecr/repository/schema/kind.go",
+				"16:",
+				">    16 | func Repository( {",
+				"     15 | ",
+				"     17 | \treturn",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			overlay := t.TempDir()
+			writeFiles(t, overlay, map[string]string{
+				"resource/schema/kind.go.tpl": tt.kindTemplate,
+			})
+			gen := generate.NewGoGenerator(generate.WithTemplateDirs(overlay))
+			files, err := gen.Generate(
+				context.TODO(),
+				[]*model.ResourceDefinition{newRepository("Name")},
+			)
+			if len(tt.expErrs) > 0 {
+				require.NotNil(err)
+				var formatErr *generate.FormatError
+				require.True(errors.As(err, &formatErr))
+				assert.Equal(t, "resource/schema/kind.go.tpl", formatErr.Template)
+				for _, expErr := range tt.expErrs {
+					assert.Contains(t, err.Error(), expErr)
+				}
+				return
+			}
+			require.Nil(err)
+			for _, f := range files {
+				if f.Path == "ecr/repository/schema/kind.go" {
+					assert.Contains(t, string(f.Contents),
+						"DO NOT EDIT."+tt.expContents)
+				}
+			}
+		})
+	}
+}
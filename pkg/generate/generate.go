@@ -0,0 +1,172 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+const (
+	// TargetGo generates Go packages from Go templates
+	TargetGo = "go"
+	// TargetOpenAPI generates an OpenAPI v3 schema for each resource
+	TargetOpenAPI = "openapi"
+	// TargetCRD generates a Kubernetes CustomResourceDefinition manifest for
+	// each resource
+	TargetCRD = "crd"
+	// TargetProto generates a Protocol Buffers definition file for each
+	// service
+	TargetProto = "proto"
+)
+
+var (
+	// Targets contains the supported generation targets
+	Targets = []string{
+		TargetGo,
+		TargetOpenAPI,
+		TargetCRD,
+		TargetProto,
+	}
+)
+
+// File is a single generated file
+type File struct {
+	// Path is the path to the file, relative to the output directory
+	Path string
+	// Contents is the generated file contents
+	Contents []byte
+}
+
+// Generator provides a standard interface for generating files from
+// discovered resource definitions
+type Generator interface {
+	Generate(context.Context, []*model.ResourceDefinition) ([]*File, error)
+}
+
+// New returns a new Generator for the supplied target
+func New(
+	target string,
+	opts ...option,
+) (Generator, error) {
+	switch target {
+	case TargetGo:
+		return NewGoGenerator(opts...), nil
+	case TargetOpenAPI:
+		return NewOpenAPIGenerator(opts...), nil
+	case TargetCRD:
+		return NewCRDGenerator(opts...), nil
+	case TargetProto:
+		return NewProtoGenerator(opts...), nil
+	}
+	return nil, fmt.Errorf(
+		"unsupported generation target %q. Supported targets are: %s",
+		target, strings.Join(Targets, ", "),
+	)
+}
+
+// resourceDescription returns the description of a resource of the supplied
+// Kind used by the generated files, e.g. "Repository is a resource of the AWS
+// ecr service"
+func resourceDescription(kind model.Kind) string {
+	return fmt.Sprintf(
+		"%s is a resource of the %s %s service", kind.Name,
+		strings.ToUpper(kind.CloudProvider), kind.Service,
+	)
+}
+
+// WriteFiles writes the supplied generated files underneath the supplied
+// output directory, creating directories as needed
+func WriteFiles(
+	outputPath string,
+	files []*File,
+) error {
+	for _, f := range files {
+		path := filepath.Join(outputPath, f.Path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, f.Contents, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckFiles returns the paths, relative to the supplied output directory, of
+// the supplied generated files that do not exist on disk or whose contents on
+// disk differ from the generated contents
+func CheckFiles(
+	outputPath string,
+	files []*File,
+) ([]string, error) {
+	res := []string{}
+	for _, f := range files {
+		b, err := os.ReadFile(filepath.Join(outputPath, f.Path))
+		if err != nil {
+			if os.IsNotExist(err) {
+				res = append(res, f.Path)
+				continue
+			}
+			return nil, err
+		}
+		if !bytes.Equal(b, f.Contents) {
+			res = append(res, f.Path)
+		}
+	}
+	return res, nil
+}
+
+// CheckOutput returns a human-readable description of each difference between
+// the supplied output directory and the supplied generated files and Lock,
+// which are stored under the supplied key in the output directory's manifest
+// and lock file. The output directory differs if the Lock differs from the
+// Lock stored under the key, if any of the generated files differ from the
+// files on disk or if any files previously generated under the key are no
+// longer generated.
+func CheckOutput(
+	outputPath string,
+	key string,
+	files []*File,
+	lock *Lock,
+) ([]string, error) {
+	oldLock, err := ReadLock(outputPath, key)
+	if err != nil {
+		return nil, err
+	}
+	res := lock.Diff(oldLock)
+	changed, err := CheckFiles(outputPath, files)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range changed {
+		res = append(res, fmt.Sprintf("file %s is out of date", path))
+	}
+	lockFile, err := lock.File(outputPath, key)
+	if err != nil {
+		return nil, err
+	}
+	stale, err := StaleFiles(outputPath, key, append(files, lockFile))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range stale {
+		res = append(res, fmt.Sprintf("file %s is no longer generated", path))
+	}
+	return res, nil
+}
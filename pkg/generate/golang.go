@@ -0,0 +1,641 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/anydotcloud/grm/pkg/names"
+	"github.com/anydotcloud/grm/pkg/path/fieldpath"
+	"github.com/anydotcloud/grm/pkg/types/resource/schema"
+	"github.com/samber/lo"
+
+	"github.com/anydotcloud/grm-generate/pkg/config"
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+const (
+	tplBoilerplate     = "boilerplate.go.tpl"
+	tplResource        = "resource/resource.go.tpl"
+	tplTypes           = "resource/types.go.tpl"
+	tplDelta           = "resource/delta.go.tpl"
+	tplKind            = "resource/schema/kind.go.tpl"
+	tplSchema          = "resource/schema/schema.go.tpl"
+	tplFieldDefinition = "resource/schema/field/definition.go.tpl"
+)
+
+var (
+	// goTemplateNames contains the paths, relative to the template
+	// directory, of the Go templates that are rendered for each resource
+	goTemplateNames = []string{
+		tplResource,
+		tplTypes,
+		tplDelta,
+		tplKind,
+		tplSchema,
+		tplFieldDefinition,
+	}
+)
+
+// goGenerator renders Go packages for each resource from a directory of Go
+// templates. It implements the `Generator` interface.
+type goGenerator struct {
+	opts option
+}
+
+// schemaData is the data passed to the schema.go.tpl template
+type schemaData struct {
+	// FieldPackage is the import path of the package containing the
+	// variables describing the resource's fields
+	FieldPackage string
+	// Fields is a map, keyed by stringified field path, of the qualified
+	// name of the variable describing the field
+	Fields map[string]string
+}
+
+// fieldData is the data passed to the definition.go.tpl template
+type fieldData struct {
+	// Name is the Go identifier of the variable describing the field
+	Name string
+	// MemberFields is a map, keyed by member field name, of the Go
+	// identifier of the variable describing the member field
+	MemberFields      map[string]string
+	FieldType         schema.FieldType
+	ElementType       schema.FieldType
+	ValueType         schema.FieldType
+	KeyType           schema.FieldType
+	IsRequired        bool
+	IsReadOnly        bool
+	IsImmutable       bool
+	IsLateInitialized bool
+	IsSecret          bool
+	// Documentation is the Go comment describing the field
+	Documentation string
+}
+
+// resourceData is the data passed to the resource.go.tpl template
+type resourceData struct {
+	// Version is the name of the Go package containing the resource type
+	Version string
+	// ResourceSchemaPackage is the import path of the package containing the
+	// resource's schema
+	ResourceSchemaPackage string
+	// Documentation is the Go comment describing the resource type
+	Documentation string
+	Kind          model.Kind
+	// Status describes how the resource determines whether it is ready,
+	// has failed or may no longer be modified
+	Status statusData
+}
+
+// typesData is the data passed to the types.go.tpl template
+type typesData struct {
+	// Version is the name of the Go package containing the resource type
+	Version string
+	Kind    model.Kind
+	// Structs contains the Go struct types for the resource's struct fields
+	// and the elements or values of its list and map of struct fields
+	Structs []*goStruct
+	// Accessors contains the typed getter and setter of each of the
+	// resource's top-level fields
+	Accessors []*goAccessor
+	// ImportsTime is true if any field contains a time.Time
+	ImportsTime bool
+}
+
+// goStruct describes a Go struct type
+type goStruct struct {
+	Name string
+	// Documentation is the Go comment describing the struct type
+	Documentation string
+	Members       []*goStructMember
+}
+
+// goStructMember describes a member field of a Go struct type
+type goStructMember struct {
+	Name     string
+	Type     string
+	JSONName string
+}
+
+// goAccessor describes the typed getter and setter of a top-level field
+type goAccessor struct {
+	// Name is the Go identifier of the field, used as the suffix of the
+	// getter and setter method names
+	Name string
+	// Path is the stringified field path of the field
+	Path string
+	// Type is the Go type of the field's value
+	Type string
+	// IsScalar is true if the getter returns a pointer to the value so that
+	// an unset field can be told apart from a zero value
+	IsScalar bool
+}
+
+// deltaData is the data passed to the delta.go.tpl template
+type deltaData struct {
+	// Version is the name of the Go package containing the resource type
+	Version string
+	Kind    model.Kind
+	// Fields contains the comparison of each of the resource's top-level
+	// fields that is not read-only
+	Fields []*deltaField
+	// ImportsReflect is true if any comparison uses the reflect package
+	ImportsReflect bool
+	// ImportsUnordered is true if any comparison ignores the order of a
+	// list's elements
+	ImportsUnordered bool
+}
+
+// deltaField describes the comparison of a field's values in two resources
+type deltaField struct {
+	// Path is the stringified field path of the field
+	Path string
+	// A is the Go expression for the field's value in the first resource
+	A string
+	// B is the Go expression for the field's value in the second resource
+	B string
+	// Compare is how the values are compared: "scalar", "time", "struct",
+	// "set", "collection" or "deep"
+	Compare string
+	// Members contains the comparison of each member field that is not
+	// read-only when Compare is "struct"
+	Members []*deltaField
+}
+
+func (g *goGenerator) Generate(
+	ctx context.Context,
+	rds []*model.ResourceDefinition,
+) ([]*File, error) {
+	fsys, err := NewTemplateFS(g.opts.templateDirs...)
+	if err != nil {
+		return nil, err
+	}
+	tpls, err := LoadGoTemplates(
+		fsys, g.opts.cfg.GetBoilerplateConfig(), goTemplateNames...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	res := []*File{}
+	for _, rd := range rds {
+		files, err := g.generateResource(tpls, rd)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, files...)
+	}
+	return res, nil
+}
+
+// generateResource renders the Go files for a single resource
+func (g *goGenerator) generateResource(
+	tpls map[string]*template.Template,
+	rd *model.ResourceDefinition,
+) ([]*File, error) {
+	resDir := path.Join(rd.Kind.Service, strings.ToLower(rd.Kind.Name))
+	schemaPackage := path.Join(g.opts.packageBase, resDir, "schema")
+	res := []*File{}
+
+	status, err := newStatusData(rd)
+	if err != nil {
+		return nil, err
+	}
+	f, err := RenderFile(
+		tpls, tplResource, rd.Kind,
+		path.Join(resDir, g.opts.apiVersion, "resource.go"),
+		resourceData{
+			Version:               g.opts.apiVersion,
+			ResourceSchemaPackage: schemaPackage,
+			Documentation:         goComment(resourceDescription(rd.Kind)),
+			Kind:                  rd.Kind,
+			Status:                status,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	res = append(res, f)
+
+	f, err = RenderFile(
+		tpls, tplTypes, rd.Kind,
+		path.Join(resDir, g.opts.apiVersion, "types.go"),
+		newTypesData(g.opts.apiVersion, rd),
+	)
+	if err != nil {
+		return nil, err
+	}
+	res = append(res, f)
+
+	f, err = RenderFile(
+		tpls, tplDelta, rd.Kind,
+		path.Join(resDir, g.opts.apiVersion, "delta.go"),
+		newDeltaData(g.opts.apiVersion, rd),
+	)
+	if err != nil {
+		return nil, err
+	}
+	res = append(res, f)
+
+	f, err = RenderFile(
+		tpls, tplKind, rd.Kind,
+		path.Join(resDir, "schema", "kind.go"), rd.Kind,
+	)
+	if err != nil {
+		return nil, err
+	}
+	res = append(res, f)
+
+	sd := schemaData{
+		FieldPackage: path.Join(schemaPackage, "field"),
+		Fields:       map[string]string{},
+	}
+	for _, fp := range rd.GetFieldPaths() {
+		sd.Fields[fp.String()] = "field." + goFieldName(fp)
+	}
+	f, err = RenderFile(
+		tpls, tplSchema, rd.Kind,
+		path.Join(resDir, "schema", "schema.go"), sd,
+	)
+	if err != nil {
+		return nil, err
+	}
+	res = append(res, f)
+
+	for _, fp := range rd.GetFieldPaths() {
+		field := rd.GetField(fp)
+		def := field.Definition
+		name := goFieldName(fp)
+		fd := fieldData{
+			Name:              name,
+			MemberFields:      map[string]string{},
+			FieldType:         def.Type,
+			ElementType:       def.ElementType,
+			ValueType:         def.ValueType,
+			KeyType:           def.KeyType,
+			IsRequired:        def.IsRequired,
+			IsReadOnly:        def.IsReadOnly,
+			IsImmutable:       def.IsImmutable,
+			IsLateInitialized: def.IsLateInitialized,
+			IsSecret:          def.IsSecret,
+			Documentation:     goComment(fieldDocumentation(name, def)),
+		}
+		for memberName := range def.MemberFieldDefinitions {
+			memberPath := fieldpath.FromString(fp.String() + "." + memberName)
+			if rd.GetField(memberPath) == nil {
+				continue
+			}
+			fd.MemberFields[memberName] = goFieldName(memberPath)
+		}
+		f, err = RenderFile(
+			tpls, tplFieldDefinition, rd.Kind,
+			path.Join(resDir, "schema", "field", strings.ToLower(name)+".go"),
+			fd,
+		)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, f)
+	}
+	return res, nil
+}
+
+// newTypesData returns the data passed to the types.go.tpl template for the
+// supplied resource
+func newTypesData(version string, rd *model.ResourceDefinition) typesData {
+	res := typesData{
+		Version: version,
+		Kind:    rd.Kind,
+	}
+	for _, fp := range rd.GetFieldPaths() {
+		if fp.Size() != 1 {
+			continue
+		}
+		def := rd.GetField(fp).Definition
+		name := names.New(fp.Back()).Camel
+		res.Accessors = append(res.Accessors, &goAccessor{
+			Name:     name,
+			Path:     fp.String(),
+			Type:     res.goType(rd.Kind.Name+name, fp.String(), def),
+			IsScalar: isGoScalar(def.Type),
+		})
+	}
+	return res
+}
+
+// goType returns the Go type of the value of a field, adding a struct type
+// with the supplied name for struct fields and for the elements or values of
+// list and map of struct fields
+func (d *typesData) goType(
+	typeName string,
+	fieldPath string,
+	def *model.FieldDefinition,
+) string {
+	switch def.Type {
+	case schema.FieldTypeList:
+		return "[]" + d.goElementType(typeName, fieldPath, def.ElementType, def)
+	case schema.FieldTypeMap:
+		keyType := "string"
+		if def.KeyType == schema.FieldTypeInt {
+			keyType = "int64"
+		}
+		return fmt.Sprintf(
+			"map[%s]%s", keyType,
+			d.goElementType(typeName, fieldPath, def.ValueType, def),
+		)
+	}
+	return d.goElementType(typeName, fieldPath, def.Type, def)
+}
+
+// goElementType returns the Go type of a singular field, list element or map
+// value of the supplied field type. The MemberFieldDefinitions of the supplied
+// field definition describe the struct members of the element.
+func (d *typesData) goElementType(
+	typeName string,
+	fieldPath string,
+	fieldType schema.FieldType,
+	def *model.FieldDefinition,
+) string {
+	switch fieldType {
+	case schema.FieldTypeStruct:
+		d.addStruct(typeName, fieldPath, def.MemberFieldDefinitions)
+		return "*" + typeName
+	case schema.FieldTypeString:
+		return "string"
+	case schema.FieldTypeBool:
+		return "bool"
+	case schema.FieldTypeInt:
+		return "int64"
+	case schema.FieldTypeFloat:
+		return "float64"
+	case schema.FieldTypeTime:
+		d.ImportsTime = true
+		return "time.Time"
+	case schema.FieldTypeList:
+		return "[]interface{}"
+	case schema.FieldTypeMap:
+		return "map[string]interface{}"
+	}
+	return "interface{}"
+}
+
+// addStruct adds a struct type with the supplied name that has a member for
+// each of the supplied member field definitions. Scalar members are pointers
+// so that unset members can be told apart from zero values.
+func (d *typesData) addStruct(
+	typeName string,
+	fieldPath string,
+	members map[string]*model.FieldDefinition,
+) {
+	s := &goStruct{
+		Name: typeName,
+		Documentation: goComment(fmt.Sprintf(
+			"%s describes the value of a %s %s field",
+			typeName, d.Kind.Name, fieldPath,
+		)),
+	}
+	d.Structs = append(d.Structs, s)
+	memberNames := lo.Keys(members)
+	sort.Strings(memberNames)
+	for _, memberName := range memberNames {
+		def := members[memberName]
+		name := names.New(memberName).Camel
+		memberType := d.goType(typeName+name, fieldPath+"."+memberName, def)
+		if isGoScalar(def.Type) {
+			memberType = "*" + memberType
+		}
+		s.Members = append(s.Members, &goStructMember{
+			Name:     name,
+			Type:     memberType,
+			JSONName: memberName,
+		})
+	}
+}
+
+// newDeltaData returns the data passed to the delta.go.tpl template for the
+// supplied resource
+func newDeltaData(version string, rd *model.ResourceDefinition) deltaData {
+	res := deltaData{
+		Version: version,
+		Kind:    rd.Kind,
+	}
+	for _, fp := range rd.GetFieldPaths() {
+		if fp.Size() != 1 {
+			continue
+		}
+		def := rd.GetField(fp).Definition
+		if def.IsReadOnly {
+			continue
+		}
+		getter := "Get" + names.New(fp.Back()).Camel + "()"
+		res.Fields = append(res.Fields, res.newDeltaField(
+			fp.String(), "a."+getter, "b."+getter, def,
+		))
+	}
+	return res
+}
+
+// newDeltaField returns the comparison of the values, given by the supplied
+// Go expressions, of a field. Struct fields are compared member by member.
+// List and map fields are compared as a whole.
+func (d *deltaData) newDeltaField(
+	fieldPath string,
+	a string,
+	b string,
+	def *model.FieldDefinition,
+) *deltaField {
+	res := &deltaField{
+		Path: fieldPath,
+		A:    a,
+		B:    b,
+	}
+	switch def.Type {
+	case schema.FieldTypeBool, schema.FieldTypeInt, schema.FieldTypeFloat,
+		schema.FieldTypeString:
+		res.Compare = "scalar"
+	case schema.FieldTypeTime:
+		res.Compare = "time"
+	case schema.FieldTypeStruct:
+		res.Compare = "struct"
+		memberNames := lo.Keys(def.MemberFieldDefinitions)
+		sort.Strings(memberNames)
+		for _, memberName := range memberNames {
+			memberDef := def.MemberFieldDefinitions[memberName]
+			if memberDef.IsReadOnly {
+				continue
+			}
+			goName := names.New(memberName).Camel
+			res.Members = append(res.Members, d.newDeltaField(
+				fieldPath+"."+memberName, a+"."+goName, b+"."+goName,
+				memberDef,
+			))
+		}
+	case schema.FieldTypeList:
+		d.ImportsReflect = true
+		if def.IsSet {
+			d.ImportsUnordered = true
+			res.Compare = "set"
+		} else {
+			res.Compare = "collection"
+		}
+	case schema.FieldTypeMap:
+		d.ImportsReflect = true
+		res.Compare = "collection"
+	default:
+		d.ImportsReflect = true
+		res.Compare = "deep"
+	}
+	return res
+}
+
+// isGoScalar returns true if values of the supplied field type are Go
+// scalars
+func isGoScalar(fieldType schema.FieldType) bool {
+	switch fieldType {
+	case schema.FieldTypeBool, schema.FieldTypeInt, schema.FieldTypeFloat,
+		schema.FieldTypeString, schema.FieldTypeTime:
+		return true
+	}
+	return false
+}
+
+// RenderFile executes the named template with the supplied data and returns
+// the generated File at the supplied path. The rendered Go source is
+// formatted with gofmt. Returns a FormatError if the rendered source for the
+// supplied resource kind cannot be formatted.
+func RenderFile(
+	tpls map[string]*template.Template,
+	tplName string,
+	kind model.Kind,
+	filePath string,
+	data interface{},
+) (*File, error) {
+	var b bytes.Buffer
+	if err := tpls[tplName].Execute(&b, data); err != nil {
+		return nil, fmt.Errorf(
+			"failed to render template %s for %s: %s",
+			tplName, filePath, err,
+		)
+	}
+	contents, err := formatGo(b.Bytes(), tplName, kind, filePath)
+	if err != nil {
+		return nil, err
+	}
+	return &File{Path: filePath, Contents: contents}, nil
+}
+
+// LoadGoTemplates returns a map, keyed by template name, of the parsed Go
+// templates with the supplied names. Every template may use the templates
+// defined in the boilerplate template. The hook templates matching
+// TemplateHooksPattern are parsed after every template so that they may
+// redefine the template's named blocks. The boilerplate template's license
+// header is described by the supplied BoilerplateConfig, or is the default
+// header if the config is nil.
+func LoadGoTemplates(
+	fsys fs.FS,
+	bc *config.BoilerplateConfig,
+	tplNames ...string,
+) (map[string]*template.Template, error) {
+	header, err := boilerplateHeader(bc)
+	if err != nil {
+		return nil, err
+	}
+	funcs := template.FuncMap{
+		"header": func() string { return header },
+	}
+	boilerplate, err := fs.ReadFile(fsys, tplBoilerplate)
+	if err != nil {
+		return nil, err
+	}
+	hookNames, err := fs.Glob(fsys, TemplateHooksPattern)
+	if err != nil {
+		return nil, err
+	}
+	hooks := make([]string, len(hookNames))
+	for x, hookName := range hookNames {
+		b, err := fs.ReadFile(fsys, hookName)
+		if err != nil {
+			return nil, err
+		}
+		hooks[x] = string(b)
+	}
+	res := map[string]*template.Template{}
+	for _, name := range tplNames {
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		t := template.New(name).Funcs(funcs)
+		if _, err := t.New(tplBoilerplate).Parse(string(boilerplate)); err != nil {
+			return nil, fmt.Errorf(
+				"failed to parse template %s: %s", tplBoilerplate, err,
+			)
+		}
+		if _, err := t.Parse(string(b)); err != nil {
+			return nil, fmt.Errorf("failed to parse template %s: %s", name, err)
+		}
+		for x, hook := range hooks {
+			if _, err := t.New(hookNames[x]).Parse(hook); err != nil {
+				return nil, fmt.Errorf(
+					"failed to parse template %s: %s", hookNames[x], err,
+				)
+			}
+		}
+		res[name] = t
+	}
+	return res, nil
+}
+
+// goFieldName returns the Go identifier for the field at the supplied field
+// path, e.g. "EncryptionConfigurationKMSKey" for the field path
+// "EncryptionConfiguration.KMSKey"
+func goFieldName(fp *fieldpath.Path) string {
+	return strings.ReplaceAll(fp.String(), ".", "")
+}
+
+// fieldDocumentation returns the documentation for a field, beginning with
+// the field's Go identifier
+func fieldDocumentation(name string, def *model.FieldDefinition) string {
+	if def.Documentation == "" {
+		return fmt.Sprintf("%s describes a %s field", name, def.TypeString())
+	}
+	return fmt.Sprintf(
+		"%s describes a %s field.\n\n%s",
+		name, def.TypeString(), def.Documentation,
+	)
+}
+
+// goComment returns the supplied text as a Go comment
+func goComment(text string) string {
+	lines := strings.Split(text, "\n")
+	for x, line := range lines {
+		lines[x] = strings.TrimRight("// "+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// NewGoGenerator returns a new Generator that renders a Go package for each
+// resource from a directory of Go templates
+func NewGoGenerator(
+	opts ...option,
+) Generator {
+	return &goGenerator{
+		opts: mergeOptions(opts),
+	}
+}
@@ -0,0 +1,360 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generate_test
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/anydotcloud/grm/pkg/path/fieldpath"
+	"github.com/anydotcloud/grm/pkg/types/resource/schema"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anydotcloud/grm-generate/pkg/config"
+	"github.com/anydotcloud/grm-generate/pkg/discover/aws/testutil"
+	"github.com/anydotcloud/grm-generate/pkg/generate"
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+// newRepository returns a minimal ResourceDefinition with a single string
+// field with the supplied name
+func newRepository(fieldName string) *model.ResourceDefinition {
+	rd := model.NewResourceDefinition(
+		nil, model.NewKind("aws", "ecr", "Repository"),
+	)
+	rd.AddField(model.NewField(
+		fieldpath.FromString(fieldName), nil,
+		&model.FieldDefinition{
+			Type:       schema.FieldTypeString,
+			IsRequired: true,
+		},
+	))
+	return rd
+}
+
+func TestGoGenerator(t *testing.T) {
+	require := require.New(t)
+
+	gen := generate.NewGoGenerator()
+	files, err := gen.Generate(
+		context.TODO(),
+		[]*model.ResourceDefinition{newRepository("Name")},
+	)
+	require.Nil(err)
+
+	paths := []string{}
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	require.Equal(
+		[]string{
+			"ecr/repository/v1/resource.go",
+			"ecr/repository/v1/types.go",
+			"ecr/repository/v1/delta.go",
+			"ecr/repository/schema/kind.go",
+			"ecr/repository/schema/schema.go",
+			"ecr/repository/schema/field/name.go",
+		},
+		paths,
+	)
+
+	dir := t.TempDir()
+	changed, err := generate.CheckFiles(dir, files)
+	require.Nil(err)
+	require.Equal(paths, changed)
+
+	require.Nil(generate.WriteFiles(dir, files))
+	changed, err = generate.CheckFiles(dir, files)
+	require.Nil(err)
+	require.Empty(changed)
+
+	require.Nil(os.WriteFile(
+		filepath.Join(dir, "ecr", "repository", "schema", "kind.go"),
+		[]byte("package schema\n"), 0644,
+	))
+	changed, err = generate.CheckFiles(dir, files)
+	require.Nil(err)
+	require.Equal([]string{"ecr/repository/schema/kind.go"}, changed)
+}
+
+func TestGoGeneratorTypes(t *testing.T) {
+	require := require.New(t)
+
+	rd := newRepository("Name")
+	tagDef := &model.FieldDefinition{
+		Type:        schema.FieldTypeList,
+		ElementType: schema.FieldTypeStruct,
+		MemberFieldDefinitions: map[string]*model.FieldDefinition{
+			"Key":   {Type: schema.FieldTypeString},
+			"Value": {Type: schema.FieldTypeString},
+		},
+	}
+	rd.AddField(model.NewField(fieldpath.FromString("Tags"), nil, tagDef))
+	rd.AddField(model.NewField(
+		fieldpath.FromString("CreatedAt"), nil,
+		&model.FieldDefinition{Type: schema.FieldTypeTime},
+	))
+
+	gen := generate.NewGoGenerator()
+	files, err := gen.Generate(
+		context.TODO(), []*model.ResourceDefinition{rd},
+	)
+	require.Nil(err)
+	var types *generate.File
+	for _, f := range files {
+		if f.Path == "ecr/repository/v1/types.go" {
+			types = f
+		}
+	}
+	require.NotNil(types)
+
+	_, err = parser.ParseFile(
+		token.NewFileSet(), types.Path, types.Contents, parser.AllErrors,
+	)
+	require.Nil(err)
+
+	contents := string(types.Contents)
+	assert.Contains(t, contents, "\t\"time\"\n")
+	assert.Contains(t, contents, "type RepositoryTags struct {\n"+
+		"\tKey   *string `json:\"Key,omitempty\"`\n"+
+		"\tValue *string `json:\"Value,omitempty\"`\n}")
+	assert.Contains(t, contents,
+		"func (r *Repository) GetCreatedAt() *time.Time {")
+	assert.Contains(t, contents,
+		"func (r *Repository) GetTags() []*RepositoryTags {")
+	assert.Contains(t, contents,
+		"func (r *Repository) SetName(v string) error {")
+}
+
+func TestGoGeneratorDelta(t *testing.T) {
+	require := require.New(t)
+
+	rd := newRepository("Name")
+	encDef := &model.FieldDefinition{
+		Type: schema.FieldTypeStruct,
+		MemberFieldDefinitions: map[string]*model.FieldDefinition{
+			"KMSKey": {Type: schema.FieldTypeString},
+			"Status": {
+				Type:       schema.FieldTypeString,
+				IsReadOnly: true,
+			},
+		},
+	}
+	rd.AddField(model.NewField(
+		fieldpath.FromString("EncryptionConfiguration"), nil, encDef,
+	))
+	rd.AddField(model.NewField(
+		fieldpath.FromString("Tags"), nil,
+		&model.FieldDefinition{
+			Type:        schema.FieldTypeList,
+			ElementType: schema.FieldTypeString,
+			IsSet:       true,
+		},
+	))
+	rd.AddField(model.NewField(
+		fieldpath.FromString("Labels"), nil,
+		&model.FieldDefinition{
+			Type:      schema.FieldTypeMap,
+			KeyType:   schema.FieldTypeString,
+			ValueType: schema.FieldTypeString,
+		},
+	))
+	rd.AddField(model.NewField(
+		fieldpath.FromString("CreatedAt"), nil,
+		&model.FieldDefinition{
+			Type:       schema.FieldTypeTime,
+			IsReadOnly: true,
+		},
+	))
+
+	gen := generate.NewGoGenerator()
+	files, err := gen.Generate(
+		context.TODO(), []*model.ResourceDefinition{rd},
+	)
+	require.Nil(err)
+	var delta *generate.File
+	for _, f := range files {
+		if f.Path == "ecr/repository/v1/delta.go" {
+			delta = f
+		}
+	}
+	require.NotNil(delta)
+
+	_, err = parser.ParseFile(
+		token.NewFileSet(), delta.Path, delta.Contents, parser.AllErrors,
+	)
+	require.Nil(err)
+
+	contents := string(delta.Contents)
+	// Read-only fields and members are not compared
+	assert.NotContains(t, contents, "CreatedAt")
+	assert.NotContains(t, contents, "Status")
+	// Struct fields are compared member by member
+	assert.Contains(t, contents,
+		"} else if a.GetEncryptionConfiguration().KMSKey != nil && "+
+			"*a.GetEncryptionConfiguration().KMSKey != "+
+			"*b.GetEncryptionConfiguration().KMSKey {\n"+
+			"\t\t\tdelta.Add(\"EncryptionConfiguration.KMSKey\"")
+	// Lists configured as sets are compared regardless of order
+	assert.Contains(t, contents,
+		"if !equalIgnoringOrder(a.GetTags(), b.GetTags()) {")
+	assert.Contains(t, contents,
+		"func equalIgnoringOrder(a interface{}, b interface{}) bool {")
+	assert.Contains(t, contents,
+		"!reflect.DeepEqual(a.GetLabels(), b.GetLabels())")
+}
+
+func TestGoGeneratorStatus(t *testing.T) {
+	// discoverTable returns the DynamoDB Table resource discovered in the
+	// DynamoDB testdata API model, using the supplied configuration. Its
+	// TableStatus field is only returned by the CreateTable operation.
+	discoverTable := func(cfg *config.Config) *model.ResourceDefinition {
+		rds := testutil.DiscoverResources(t, cfg, "dynamodb")
+		table, found := lo.Find(rds, func(rd *model.ResourceDefinition) bool {
+			return rd.Kind.Name == "Table"
+		})
+		require.True(t, found)
+		return table
+	}
+
+	newJob := func(statusPath string) *model.ResourceDefinition {
+		rd := model.NewResourceDefinition(
+			&config.ResourceConfig{
+				Status: &config.StatusConfig{
+					Path:         statusPath,
+					ReadyValues:  []string{"OK"},
+					FailedValues: []string{"BROKEN"},
+				},
+			},
+			model.NewKind("aws", "batch", "Job"),
+		)
+		codeDef := &model.FieldDefinition{Type: schema.FieldTypeString}
+		rd.AddField(model.NewField(
+			fieldpath.FromString("Status"), nil,
+			&model.FieldDefinition{
+				Type: schema.FieldTypeStruct,
+				MemberFieldDefinitions: map[string]*model.FieldDefinition{
+					"Code": codeDef,
+				},
+			},
+		))
+		rd.AddField(model.NewField(
+			fieldpath.FromString("Status.Code"), nil, codeDef,
+		))
+		return rd
+	}
+
+	tests := []struct {
+		name      string
+		rd        *model.ResourceDefinition
+		expectErr bool
+		contains  []string
+		excludes  []string
+	}{
+		{
+			"no status field",
+			newRepository("Name"),
+			false,
+			[]string{
+				"IsReady() bool {\n\treturn true\n}",
+				"IsImmutable() bool {\n\treturn false\n}",
+			},
+			[]string{"status()"},
+		},
+		{
+			"status rules inferred from status-like enum field",
+			discoverTable(nil),
+			false,
+			[]string{
+				"case \"ACTIVE\":\n\t\treturn true",
+				"case \"DELETING\", \"ARCHIVING\", \"ARCHIVED\":\n" +
+					"\t\treturn true",
+				"return *r.GetTableStatus()",
+				// No TableStatus value indicates failure
+				"IsValid() bool {\n\treturn len(r.errors) == 0\n}",
+			},
+			nil,
+		},
+		{
+			"configured output-only status field",
+			discoverTable(config.New(config.WithYAML(`
+resources:
+  Table:
+    status:
+      path: ArchivalSummary.ArchivalReason
+      terminal_values:
+        - ARCHIVED_BY_USER
+`))),
+			false,
+			[]string{
+				"case \"ARCHIVED_BY_USER\":\n\t\treturn true",
+				"if r.GetArchivalSummary() == nil {",
+				"return *r.GetArchivalSummary().ArchivalReason",
+			},
+			nil,
+		},
+		{
+			"configured nested status field",
+			newJob("Status.Code"),
+			false,
+			[]string{
+				"case \"OK\":\n\t\treturn true",
+				"case \"BROKEN\":\n\t\treturn false",
+				"if r.GetStatus() == nil {",
+				"if r.GetStatus().Code == nil {",
+				"return *r.GetStatus().Code",
+			},
+			nil,
+		},
+		{
+			"configured status field does not exist",
+			newJob("Status.Unknown"),
+			true,
+			nil,
+			nil,
+		},
+		{
+			"configured status field is not a string",
+			newJob("Status"),
+			true,
+			nil,
+			nil,
+		},
+	}
+	gen := generate.NewGoGenerator()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+			files, err := gen.Generate(
+				context.TODO(), []*model.ResourceDefinition{test.rd},
+			)
+			if test.expectErr {
+				require.NotNil(err)
+				return
+			}
+			require.Nil(err)
+			contents := string(files[0].Contents)
+			for _, s := range test.contains {
+				assert.Contains(t, contents, s)
+			}
+			for _, s := range test.excludes {
+				assert.NotContains(t, contents, s)
+			}
+		})
+	}
+}
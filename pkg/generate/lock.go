@@ -0,0 +1,203 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/samber/lo"
+
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+const (
+	// LockFileName is the name of the lock file written to the output
+	// directory. The lock file contains a Lock for each set of generated
+	// files, keyed like the manifest.
+	LockFileName = "grm-generate.lock"
+)
+
+// lockFile is a map, keyed by the key identifying a set of generated files,
+// such as "go aws/ecr", of the Lock recording the inputs of each set
+type lockFile map[string]*Lock
+
+// Lock records the inputs that produced a set of generated files so that
+// regeneration can be reviewed and checked
+type Lock struct {
+	// Version is the version of grm-generate that generated the files
+	Version string `json:"version"`
+	// SDK describes the cloud provider SDK that resources were discovered
+	// from
+	SDK *SDKLock `json:"sdk,omitempty"`
+	// ConfigHash is the SHA256 hash of the configuration file, or the empty
+	// string if no configuration file was used
+	ConfigHash string `json:"config_hash,omitempty"`
+	// Resources is a map, keyed by "<cloud provider>/<service>/<name>", of
+	// the SHA256 hash of each ResourceDefinition the files were generated
+	// from
+	Resources map[string]string `json:"resources"`
+}
+
+// SDKLock describes the version of a cloud provider SDK
+type SDKLock struct {
+	// Repository is the URL of the SDK's source repository
+	Repository string `json:"repository"`
+	// Tag is the Git tag of the SDK, if the checked out commit is tagged
+	Tag string `json:"tag,omitempty"`
+	// Commit is the Git commit hash of the SDK
+	Commit string `json:"commit"`
+}
+
+// NewLock returns a Lock for the supplied resources. The supplied SDK
+// description and configuration file path are optional.
+func NewLock(
+	version string,
+	sdk *SDKLock,
+	configPath string,
+	rds []*model.ResourceDefinition,
+) (*Lock, error) {
+	res := &Lock{
+		Version:   version,
+		SDK:       sdk,
+		Resources: map[string]string{},
+	}
+	if configPath != "" {
+		b, err := os.ReadFile(configPath)
+		if err != nil {
+			return nil, err
+		}
+		res.ConfigHash = hash(b)
+	}
+	for _, rd := range rds {
+		b, err := model.MarshalJSON([]*model.ResourceDefinition{rd})
+		if err != nil {
+			return nil, err
+		}
+		res.Resources[lockKey(rd.Kind)] = hash(b)
+	}
+	return res, nil
+}
+
+// ReadLock returns the Lock stored under the supplied key in the lock file in
+// the supplied output directory, or nil if there is no lock file or no Lock
+// stored under the key
+func ReadLock(outputPath string, key string) (*Lock, error) {
+	locks, err := readLockFile(outputPath)
+	if err != nil {
+		return nil, err
+	}
+	return locks[key], nil
+}
+
+// File returns the lock file in the supplied output directory as a generated
+// File, with this Lock stored under the supplied key. The Locks stored under
+// other keys are kept.
+func (l *Lock) File(outputPath string, key string) (*File, error) {
+	locks, err := readLockFile(outputPath)
+	if err != nil {
+		return nil, err
+	}
+	locks[key] = l
+	b, err := yaml.Marshal(locks)
+	if err != nil {
+		return nil, err
+	}
+	return &File{Path: LockFileName, Contents: b}, nil
+}
+
+// readLockFile returns the Locks in the lock file in the supplied output
+// directory, or an empty map if there is no lock file
+func readLockFile(outputPath string) (lockFile, error) {
+	res := lockFile{}
+	b, err := os.ReadFile(filepath.Join(outputPath, LockFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return res, nil
+		}
+		return nil, err
+	}
+	if err := yaml.Unmarshal(b, &res); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %s", LockFileName, err)
+	}
+	return res, nil
+}
+
+// Diff returns a sorted, human-readable description of each difference
+// between this Lock and a supplied other Lock. A nil other Lock differs from
+// every Lock.
+func (l *Lock) Diff(other *Lock) []string {
+	if other == nil {
+		return []string{"no lock file"}
+	}
+	res := []string{}
+	if l.Version != other.Version {
+		res = append(res, fmt.Sprintf(
+			"grm-generate version changed from %s to %s",
+			other.Version, l.Version,
+		))
+	}
+	if sdkString(l.SDK) != sdkString(other.SDK) {
+		res = append(res, fmt.Sprintf(
+			"SDK changed from %s to %s",
+			sdkString(other.SDK), sdkString(l.SDK),
+		))
+	}
+	if l.ConfigHash != other.ConfigHash {
+		res = append(res, "configuration file changed")
+	}
+	keys := lo.Uniq(append(lo.Keys(l.Resources), lo.Keys(other.Resources)...))
+	sort.Strings(keys)
+	for _, key := range keys {
+		newHash, inNew := l.Resources[key]
+		oldHash, inOld := other.Resources[key]
+		switch {
+		case !inOld:
+			res = append(res, fmt.Sprintf("resource %s added", key))
+		case !inNew:
+			res = append(res, fmt.Sprintf("resource %s removed", key))
+		case newHash != oldHash:
+			res = append(res, fmt.Sprintf("resource %s changed", key))
+		}
+	}
+	return res
+}
+
+// lockKey returns the key of the supplied Kind in the Lock's Resources map
+func lockKey(kind model.Kind) string {
+	return strings.Join(
+		[]string{kind.CloudProvider, kind.Service, kind.Name}, "/",
+	)
+}
+
+// sdkString returns a human-readable description of the supplied SDK
+func sdkString(sdk *SDKLock) string {
+	if sdk == nil {
+		return "(none)"
+	}
+	if sdk.Tag != "" {
+		return fmt.Sprintf("%s@%s (%s)", sdk.Repository, sdk.Tag, sdk.Commit)
+	}
+	return fmt.Sprintf("%s@%s", sdk.Repository, sdk.Commit)
+}
+
+// hash returns the hex-encoded SHA256 hash of the supplied bytes
+func hash(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,179 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generate_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anydotcloud/grm-generate/pkg/config"
+	"github.com/anydotcloud/grm-generate/pkg/discover/aws/testutil"
+	"github.com/anydotcloud/grm-generate/pkg/generate"
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+// discoverECR returns the resources discovered in the ECR testdata API model,
+// the PullThroughCacheRule and Repository resources, using the supplied
+// configuration
+func discoverECR(t *testing.T, cfg *config.Config) []*model.ResourceDefinition {
+	rds := testutil.DiscoverResources(t, cfg, "ecr")
+	require.Len(t, rds, 2)
+	return rds
+}
+
+func TestLockDiff(t *testing.T) {
+	require := require.New(t)
+
+	sdk := &generate.SDKLock{
+		Repository: "https://github.com/aws/aws-sdk-go",
+		Tag:        "v1.44.93",
+		Commit:     "abc123",
+	}
+	base, err := generate.NewLock("v0.1.0", sdk, "", discoverECR(t, config.New()))
+	require.Nil(err)
+
+	// Resource hashes must be stable across runs for the same input
+	same, err := generate.NewLock("v0.1.0", sdk, "", discoverECR(t, config.New()))
+	require.Nil(err)
+	require.Equal(base.Resources, same.Resources)
+
+	tests := []struct {
+		name   string
+		other  *generate.Lock
+		expect []string
+	}{
+		{
+			"no lock file",
+			nil,
+			[]string{"no lock file"},
+		},
+		{
+			"identical",
+			same,
+			[]string{},
+		},
+		{
+			"version and SDK changed",
+			&generate.Lock{
+				Version: "v0.0.9",
+				SDK: &generate.SDKLock{
+					Repository: "https://github.com/aws/aws-sdk-go",
+					Commit:     "def456",
+				},
+				Resources: base.Resources,
+			},
+			[]string{
+				"grm-generate version changed from v0.0.9 to v0.1.0",
+				"SDK changed from https://github.com/aws/aws-sdk-go@def456 to " +
+					"https://github.com/aws/aws-sdk-go@v1.44.93 (abc123)",
+			},
+		},
+		{
+			"resources added, removed and changed",
+			&generate.Lock{
+				Version: "v0.1.0",
+				SDK:     sdk,
+				Resources: map[string]string{
+					"aws/ecr/PullThroughCacheRule": base.Resources["aws/ecr/PullThroughCacheRule"],
+					"aws/ecr/Repository":           "changed",
+					"aws/ecr/LifecyclePolicy":      "removed",
+				},
+			},
+			[]string{
+				"resource aws/ecr/LifecyclePolicy removed",
+				"resource aws/ecr/Repository changed",
+			},
+		},
+		{
+			"configuration changed",
+			&generate.Lock{
+				Version:    "v0.1.0",
+				SDK:        sdk,
+				ConfigHash: "deadbeef",
+				Resources:  base.Resources,
+			},
+			[]string{"configuration file changed"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expect, base.Diff(test.other))
+		})
+	}
+
+	added, err := generate.NewLock("v0.1.0", sdk, "", nil)
+	require.Nil(err)
+	require.Equal(
+		[]string{
+			"resource aws/ecr/PullThroughCacheRule added",
+			"resource aws/ecr/Repository added",
+		},
+		base.Diff(added),
+	)
+
+	// Renaming a field changes only the Repository resource
+	changed, err := generate.NewLock(
+		"v0.1.0", sdk, "", discoverECR(t, config.New(config.WithYAML(`
+resources:
+  Repository:
+    fields:
+      Name:
+        renames:
+          - RepositoryName
+`))),
+	)
+	require.Nil(err)
+	require.Equal(
+		[]string{"resource aws/ecr/Repository changed"}, changed.Diff(base),
+	)
+}
+
+func TestReadLock(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	got, err := generate.ReadLock(dir, "go aws/ecr")
+	require.Nil(err)
+	require.Nil(got)
+
+	lock, err := generate.NewLock("v0.1.0", nil, "", discoverECR(t, config.New()))
+	require.Nil(err)
+	f, err := lock.File(dir, "go aws/ecr")
+	require.Nil(err)
+	require.Equal(generate.LockFileName, f.Path)
+	require.Nil(generate.WriteFiles(dir, []*generate.File{f}))
+
+	got, err = generate.ReadLock(dir, "go aws/ecr")
+	require.Nil(err)
+	require.Equal(lock, got)
+	require.Empty(lock.Diff(got))
+
+	// Storing a Lock under another key keeps the Lock stored under the first
+	// key
+	other, err := generate.NewLock("v0.1.0", nil, "", nil)
+	require.Nil(err)
+	f, err = other.File(dir, "openapi aws/ecr")
+	require.Nil(err)
+	require.Nil(generate.WriteFiles(dir, []*generate.File{f}))
+
+	got, err = generate.ReadLock(dir, "go aws/ecr")
+	require.Nil(err)
+	require.Equal(lock, got)
+	got, err = generate.ReadLock(dir, "openapi aws/ecr")
+	require.Nil(err)
+	require.Equal(other, got)
+	got, err = generate.ReadLock(dir, "go aws/s3")
+	require.Nil(err)
+	require.Nil(got)
+}
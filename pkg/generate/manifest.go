@@ -0,0 +1,248 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+const (
+	// ManifestFileName is the name of the manifest file, listing the paths
+	// of the generated files, written to the output directory
+	ManifestFileName = "grm-generate.manifest"
+)
+
+// SyncSummary describes the changes made to an output directory when
+// synchronizing it with a set of generated files. Each field contains paths
+// relative to the output directory.
+type SyncSummary struct {
+	// Created contains the generated files that did not exist on disk
+	Created []string
+	// Updated contains the generated files whose contents on disk differed
+	// from the generated contents
+	Updated []string
+	// Deleted contains the previously generated files that are no longer
+	// generated
+	Deleted []string
+	// Unchanged contains the generated files whose contents on disk were
+	// already the generated contents
+	Unchanged []string
+}
+
+// String returns the number of created, updated, deleted and unchanged files
+func (s *SyncSummary) String() string {
+	return fmt.Sprintf(
+		"%d created, %d updated, %d deleted, %d unchanged",
+		len(s.Created), len(s.Updated), len(s.Deleted), len(s.Unchanged),
+	)
+}
+
+// SyncFiles synchronizes the supplied output directory with the supplied
+// generated files. Only files that do not exist on disk or whose contents
+// differ from the generated contents are written, so the modification times
+// of unchanged files are preserved. Files listed under the supplied key in the
+// output directory's manifest that are no longer generated are deleted, along
+// with any directories left empty. The manifest is then updated to list the
+// supplied files under the key.
+//
+// The key identifies the set of generated files, such as the files generated
+// for one service and target, so that several sets can be generated into the
+// same output directory without deleting each other's files.
+func SyncFiles(
+	outputPath string,
+	key string,
+	files []*File,
+) (*SyncSummary, error) {
+	res := &SyncSummary{
+		Created:   []string{},
+		Updated:   []string{},
+		Deleted:   []string{},
+		Unchanged: []string{},
+	}
+	for _, f := range files {
+		path := filepath.Join(outputPath, f.Path)
+		b, err := os.ReadFile(path)
+		switch {
+		case err == nil && bytes.Equal(b, f.Contents):
+			res.Unchanged = append(res.Unchanged, f.Path)
+			continue
+		case err == nil:
+			res.Updated = append(res.Updated, f.Path)
+		case os.IsNotExist(err):
+			res.Created = append(res.Created, f.Path)
+		default:
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, f.Contents, 0644); err != nil {
+			return nil, err
+		}
+	}
+	stale, err := StaleFiles(outputPath, key, files)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range stale {
+		if err := removeFile(outputPath, p); err != nil {
+			return nil, err
+		}
+		res.Deleted = append(res.Deleted, p)
+	}
+	listed, err := readManifest(outputPath)
+	if err != nil {
+		return nil, err
+	}
+	listed[key] = lo.Map(files, func(f *File, _ int) string {
+		return filepath.ToSlash(filepath.Clean(f.Path))
+	})
+	manifest := newManifest(listed)
+	b, err := os.ReadFile(filepath.Join(outputPath, ManifestFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if !bytes.Equal(b, manifest.Contents) {
+		if err := WriteFiles(outputPath, []*File{manifest}); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// StaleFiles returns the sorted paths, relative to the supplied output
+// directory, of the files listed under the supplied key in the output
+// directory's manifest that are not among the supplied generated files and
+// still exist on disk. Files that are also listed under another key are still
+// generated by that key and are not stale.
+func StaleFiles(
+	outputPath string,
+	key string,
+	files []*File,
+) ([]string, error) {
+	listed, err := readManifest(outputPath)
+	if err != nil {
+		return nil, err
+	}
+	generated := lo.SliceToMap(files, func(f *File) (string, bool) {
+		return filepath.ToSlash(filepath.Clean(f.Path)), true
+	})
+	for k, paths := range listed {
+		if k == key {
+			continue
+		}
+		for _, p := range paths {
+			generated[p] = true
+		}
+	}
+	res := []string{}
+	for _, p := range listed[key] {
+		if generated[p] {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(outputPath, p)); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		res = append(res, p)
+	}
+	return res, nil
+}
+
+// newManifest returns the manifest file listing the supplied generated file
+// paths under a `[key]` line for each of their keys. Keys without any paths
+// are omitted.
+func newManifest(listed map[string][]string) *File {
+	keys := lo.Keys(listed)
+	sort.Strings(keys)
+	var b bytes.Buffer
+	b.WriteString("# Code generated by grm-generate. DO NOT EDIT.\n")
+	for _, key := range keys {
+		paths := lo.Uniq(listed[key])
+		if len(paths) == 0 {
+			continue
+		}
+		sort.Strings(paths)
+		b.WriteString("[" + key + "]\n")
+		for _, p := range paths {
+			b.WriteString(p + "\n")
+		}
+	}
+	return &File{Path: ManifestFileName, Contents: b.Bytes()}
+}
+
+// readManifest returns the sorted paths listed in the manifest in the
+// supplied output directory, by key, or an empty map if there is no manifest.
+// Paths listed before any `[key]` line have the empty key. Paths that are
+// absolute or outside of the output directory are rejected.
+func readManifest(outputPath string) (map[string][]string, error) {
+	b, err := os.ReadFile(filepath.Join(outputPath, ManifestFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string][]string{}, nil
+		}
+		return nil, err
+	}
+	res := map[string][]string{}
+	key := ""
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			key = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		p := filepath.ToSlash(filepath.Clean(line))
+		if filepath.IsAbs(p) || p == ".." || strings.HasPrefix(p, "../") {
+			return nil, fmt.Errorf(
+				"failed to read %s: path %s is outside of the output "+
+					"directory", ManifestFileName, line,
+			)
+		}
+		res[key] = append(res[key], p)
+	}
+	for k, paths := range res {
+		sort.Strings(paths)
+		res[k] = lo.Uniq(paths)
+	}
+	return res, nil
+}
+
+// removeFile removes the file at the supplied path, relative to the supplied
+// output directory, and then each of its parent directories, up to the output
+// directory, that is left empty
+func removeFile(outputPath string, p string) error {
+	if err := os.Remove(filepath.Join(outputPath, p)); err != nil {
+		return err
+	}
+	for dir := filepath.Dir(p); dir != "." && dir != "/"; dir = filepath.Dir(dir) {
+		entries, err := os.ReadDir(filepath.Join(outputPath, dir))
+		if err != nil || len(entries) > 0 {
+			return nil
+		}
+		if err := os.Remove(filepath.Join(outputPath, dir)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generate_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/anydotcloud/grm-generate/pkg/config"
+	"github.com/anydotcloud/grm-generate/pkg/discover/aws/testutil"
+	"github.com/anydotcloud/grm-generate/pkg/generate"
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+func TestSyncFiles(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	files := []*generate.File{
+		{Path: "ecr/repository/v1/resource.go", Contents: []byte("a")},
+		{Path: "ecr/repository/v1/types.go", Contents: []byte("b")},
+		{Path: "s3/bucket/v1/resource.go", Contents: []byte("c")},
+	}
+
+	summary, err := generate.SyncFiles(dir, "go aws/ecr", files)
+	require.Nil(err)
+	require.Equal(
+		[]string{
+			"ecr/repository/v1/resource.go",
+			"ecr/repository/v1/types.go",
+			"s3/bucket/v1/resource.go",
+		},
+		summary.Created,
+	)
+	require.Empty(summary.Updated)
+	require.Empty(summary.Deleted)
+	require.Empty(summary.Unchanged)
+	require.FileExists(filepath.Join(dir, generate.ManifestFileName))
+
+	// Unchanged files are not rewritten
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, f := range files {
+		require.Nil(os.Chtimes(filepath.Join(dir, f.Path), past, past))
+	}
+	summary, err = generate.SyncFiles(dir, "go aws/ecr", files)
+	require.Nil(err)
+	require.Empty(summary.Created)
+	require.Empty(summary.Updated)
+	require.Empty(summary.Deleted)
+	require.Len(summary.Unchanged, 3)
+	require.Equal("0 created, 0 updated, 0 deleted, 3 unchanged", summary.String())
+	for _, f := range files {
+		fi, err := os.Stat(filepath.Join(dir, f.Path))
+		require.Nil(err)
+		require.True(fi.ModTime().Equal(past), f.Path)
+	}
+
+	// Files that are no longer generated are deleted along with any
+	// directories left empty
+	files = []*generate.File{
+		{Path: "ecr/repository/v1/resource.go", Contents: []byte("changed")},
+		{Path: "ecr/repository/v1/types.go", Contents: []byte("b")},
+	}
+	stale, err := generate.StaleFiles(dir, "go aws/ecr", files)
+	require.Nil(err)
+	require.Equal([]string{"s3/bucket/v1/resource.go"}, stale)
+
+	summary, err = generate.SyncFiles(dir, "go aws/ecr", files)
+	require.Nil(err)
+	require.Empty(summary.Created)
+	require.Equal([]string{"ecr/repository/v1/resource.go"}, summary.Updated)
+	require.Equal([]string{"s3/bucket/v1/resource.go"}, summary.Deleted)
+	require.Equal([]string{"ecr/repository/v1/types.go"}, summary.Unchanged)
+	require.NoDirExists(filepath.Join(dir, "s3"))
+	b, err := os.ReadFile(filepath.Join(dir, "ecr/repository/v1/resource.go"))
+	require.Nil(err)
+	require.Equal("changed", string(b))
+
+	stale, err = generate.StaleFiles(dir, "go aws/ecr", files)
+	require.Nil(err)
+	require.Empty(stale)
+}
+
+func TestSyncFilesKeepsUnlistedFiles(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	// A file written by hand alongside the generated files is not listed in
+	// the manifest and is never deleted
+	handwritten := filepath.Join(dir, "ecr", "repository", "v1", "custom.go")
+	require.Nil(os.MkdirAll(filepath.Dir(handwritten), 0o755))
+	require.Nil(os.WriteFile(handwritten, []byte("custom"), 0o644))
+
+	_, err := generate.SyncFiles(dir, "go aws/ecr", []*generate.File{
+		{Path: "ecr/repository/v1/resource.go", Contents: []byte("a")},
+	})
+	require.Nil(err)
+	summary, err := generate.SyncFiles(dir, "go aws/ecr", []*generate.File{})
+	require.Nil(err)
+	require.Equal([]string{"ecr/repository/v1/resource.go"}, summary.Deleted)
+	require.FileExists(handwritten)
+}
+
+func TestSyncFilesInvalidManifest(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, os.WriteFile(
+		filepath.Join(dir, generate.ManifestFileName),
+		[]byte("../outside.go\n"), 0o644,
+	))
+	_, err := generate.SyncFiles(dir, "go aws/ecr", []*generate.File{})
+	require.NotNil(t, err)
+}
+
+func TestSyncFilesServices(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	dir := t.TempDir()
+	discoverService := func(svc string) []*model.ResourceDefinition {
+		rds := testutil.DiscoverResources(t, config.New(), svc)
+		require.NotEmpty(rds)
+		return rds
+	}
+	generateFiles := func(
+		target string,
+		rds []*model.ResourceDefinition,
+	) ([]*generate.File, *generate.Lock) {
+		gen, err := generate.New(target)
+		require.Nil(err)
+		files, err := gen.Generate(ctx, rds)
+		require.Nil(err)
+		lock, err := generate.NewLock("v0.1.0", nil, "", rds)
+		require.Nil(err)
+		return files, lock
+	}
+	// syncFiles writes the supplied files and lock, as the generate command
+	// does, and returns the written files
+	syncFiles := func(
+		key string,
+		files []*generate.File,
+		lock *generate.Lock,
+	) ([]*generate.File, *generate.SyncSummary) {
+		lockFile, err := lock.File(dir, key)
+		require.Nil(err)
+		files = append(files, lockFile)
+		summary, err := generate.SyncFiles(dir, key, files)
+		require.Nil(err)
+		return files, summary
+	}
+	requireFilesExist := func(files []*generate.File) {
+		for _, f := range files {
+			require.FileExists(filepath.Join(dir, f.Path))
+		}
+	}
+
+	ecrRDs := discoverService("ecr")
+	dynamoRDs := discoverService("dynamodb")
+	ecrFiles, ecrLock := generateFiles(generate.TargetGo, ecrRDs)
+	dynamoFiles, dynamoLock := generateFiles(generate.TargetGo, dynamoRDs)
+
+	// Generating a second service into the same directory does not delete
+	// the files of the first
+	ecrFiles, _ = syncFiles("go aws/ecr", ecrFiles, ecrLock)
+	dynamoFiles, summary := syncFiles(
+		"go aws/dynamodb", dynamoFiles, dynamoLock,
+	)
+	require.Empty(summary.Deleted)
+	requireFilesExist(ecrFiles)
+	requireFilesExist(dynamoFiles)
+
+	// Generating another target does not delete the files of the first
+	// target
+	openAPIFiles, openAPILock := generateFiles(generate.TargetOpenAPI, ecrRDs)
+	openAPIFiles, summary = syncFiles(
+		"openapi aws/ecr", openAPIFiles, openAPILock,
+	)
+	require.Empty(summary.Deleted)
+	requireFilesExist(ecrFiles)
+	requireFilesExist(openAPIFiles)
+
+	// Only the files of the regenerated service and target that are no
+	// longer generated are deleted
+	removed := ecrRDs[0].Kind.Name
+	ecrFiles, ecrLock = generateFiles(generate.TargetGo, ecrRDs[1:])
+	ecrFiles, summary = syncFiles("go aws/ecr", ecrFiles, ecrLock)
+	require.NotEmpty(summary.Deleted)
+	for _, p := range summary.Deleted {
+		require.True(
+			strings.HasPrefix(p, "ecr/"+strings.ToLower(removed)+"/"), p,
+		)
+	}
+	requireFilesExist(ecrFiles)
+	requireFilesExist(dynamoFiles)
+	requireFilesExist(openAPIFiles)
+
+	// Each service and target is up to date, as `generate --check` reports
+	for _, test := range []struct {
+		key string
+		rds []*model.ResourceDefinition
+	}{
+		{"go aws/ecr", ecrRDs[1:]},
+		{"go aws/dynamodb", dynamoRDs},
+		{"openapi aws/ecr", ecrRDs},
+	} {
+		target := strings.Fields(test.key)[0]
+		files, lock := generateFiles(target, test.rds)
+		diffs, err := generate.CheckOutput(dir, test.key, files, lock)
+		require.Nil(err)
+		require.Empty(diffs, test.key)
+	}
+
+	// A service whose resources changed is reported as out of date
+	files, lock := generateFiles(generate.TargetGo, ecrRDs)
+	diffs, err := generate.CheckOutput(dir, "go aws/ecr", files, lock)
+	require.Nil(err)
+	require.Contains(diffs, "resource aws/ecr/"+removed+" added")
+}
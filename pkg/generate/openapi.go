@@ -0,0 +1,207 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/anydotcloud/grm/pkg/types/resource/schema"
+
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+// OpenAPISchema is an OpenAPI v3 Schema Object. Only the subset of keywords
+// that is also valid JSON Schema is used, so an OpenAPISchema can be consumed
+// by both OpenAPI and JSON Schema tooling.
+type OpenAPISchema struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Format      string `json:"format,omitempty"`
+	// Enum contains the allowed values, if restricted to a fixed set
+	Enum []string `json:"enum,omitempty"`
+	// Items describes the elements of an array
+	Items *OpenAPISchema `json:"items,omitempty"`
+	// Properties is a map, keyed by member field name, of the members of an
+	// object
+	Properties map[string]*OpenAPISchema `json:"properties,omitempty"`
+	// AdditionalProperties describes the values of a map
+	AdditionalProperties *OpenAPISchema `json:"additionalProperties,omitempty"`
+	// Required contains the sorted names of the required Properties
+	Required []string `json:"required,omitempty"`
+	ReadOnly bool     `json:"readOnly,omitempty"`
+	// XKubernetesValidations contains the CEL validation rules of a
+	// Kubernetes CustomResourceDefinition schema
+	XKubernetesValidations []*ValidationRule `json:"x-kubernetes-validations,omitempty"`
+	// XKubernetesPreserveUnknownFields allows any value in a Kubernetes
+	// CustomResourceDefinition schema
+	XKubernetesPreserveUnknownFields bool `json:"x-kubernetes-preserve-unknown-fields,omitempty"`
+}
+
+// ValidationRule is a Kubernetes CustomResourceDefinition CEL validation rule
+type ValidationRule struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message,omitempty"`
+}
+
+// openAPIGenerator writes an OpenAPI v3 schema for each resource. It
+// implements the `Generator` interface.
+type openAPIGenerator struct {
+	opts option
+}
+
+func (g *openAPIGenerator) Generate(
+	ctx context.Context,
+	rds []*model.ResourceDefinition,
+) ([]*File, error) {
+	res := []*File{}
+	for _, rd := range rds {
+		b, err := json.MarshalIndent(NewOpenAPISchema(rd), "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to marshal OpenAPI schema for %s: %s",
+				rd.Kind.Name, err,
+			)
+		}
+		res = append(res, &File{
+			Path: path.Join(
+				rd.Kind.Service, strings.ToLower(rd.Kind.Name)+".json",
+			),
+			Contents: append(b, '\n'),
+		})
+	}
+	return res, nil
+}
+
+// NewOpenAPISchema returns an OpenAPI v3 schema describing an object with a
+// property for each of the supplied resource's top-level fields
+func NewOpenAPISchema(rd *model.ResourceDefinition) *OpenAPISchema {
+	members := map[string]*model.FieldDefinition{}
+	for _, fp := range rd.GetFieldPaths() {
+		if fp.Size() != 1 {
+			continue
+		}
+		members[fp.Back()] = rd.GetField(fp).Definition
+	}
+	res := &OpenAPISchema{
+		Title:       rd.Kind.Name,
+		Description: resourceDescription(rd.Kind),
+	}
+	setOpenAPIObject(res, members)
+	return res
+}
+
+// newOpenAPIFieldSchema returns the OpenAPI v3 schema for the supplied field
+// definition
+func newOpenAPIFieldSchema(def *model.FieldDefinition) *OpenAPISchema {
+	res := &OpenAPISchema{
+		Description: def.Documentation,
+		ReadOnly:    def.IsReadOnly,
+	}
+	switch def.Type {
+	case schema.FieldTypeList:
+		// The MemberFieldDefinitions of a list of structs describe the
+		// members of the list elements
+		res.Type = "array"
+		res.Items = newOpenAPIElementSchema(
+			def.ElementType, def.MemberFieldDefinitions, def.EnumValues,
+		)
+	case schema.FieldTypeMap:
+		// The MemberFieldDefinitions of a map of structs describe the members
+		// of the map values
+		res.Type = "object"
+		res.AdditionalProperties = newOpenAPIElementSchema(
+			def.ValueType, def.MemberFieldDefinitions, def.EnumValues,
+		)
+	case schema.FieldTypeStruct:
+		setOpenAPIObject(res, def.MemberFieldDefinitions)
+	default:
+		res.Type, res.Format = openAPITypeAndFormat(def.Type)
+		res.Enum = def.EnumValues
+	}
+	return res
+}
+
+// newOpenAPIElementSchema returns the OpenAPI v3 schema for a list element or
+// map value of the supplied type. The supplied member field definitions are
+// used when the element is a struct and the supplied enum values are used
+// when the element is a scalar.
+func newOpenAPIElementSchema(
+	fieldType schema.FieldType,
+	members map[string]*model.FieldDefinition,
+	enumValues []string,
+) *OpenAPISchema {
+	res := &OpenAPISchema{}
+	switch fieldType {
+	case schema.FieldTypeList:
+		res.Type = "array"
+	case schema.FieldTypeStruct:
+		setOpenAPIObject(res, members)
+	default:
+		res.Type, res.Format = openAPITypeAndFormat(fieldType)
+		res.Enum = enumValues
+	}
+	return res
+}
+
+// setOpenAPIObject sets the supplied schema to an object with a property for
+// each of the supplied member field definitions
+func setOpenAPIObject(
+	s *OpenAPISchema,
+	members map[string]*model.FieldDefinition,
+) {
+	s.Type = "object"
+	if len(members) == 0 {
+		return
+	}
+	s.Properties = make(map[string]*OpenAPISchema, len(members))
+	for name, def := range members {
+		s.Properties[name] = newOpenAPIFieldSchema(def)
+		if def.IsRequired {
+			s.Required = append(s.Required, name)
+		}
+	}
+	sort.Strings(s.Required)
+}
+
+// openAPITypeAndFormat returns the OpenAPI v3 type and format of the supplied
+// scalar field type. An empty type allows any value.
+func openAPITypeAndFormat(fieldType schema.FieldType) (string, string) {
+	switch fieldType {
+	case schema.FieldTypeBool:
+		return "boolean", ""
+	case schema.FieldTypeInt:
+		return "integer", "int64"
+	case schema.FieldTypeFloat:
+		return "number", "double"
+	case schema.FieldTypeString:
+		return "string", ""
+	case schema.FieldTypeTime:
+		return "string", "date-time"
+	}
+	return "", ""
+}
+
+// NewOpenAPIGenerator returns a new Generator that writes an OpenAPI v3
+// schema for each resource
+func NewOpenAPIGenerator(
+	opts ...option,
+) Generator {
+	return &openAPIGenerator{
+		opts: mergeOptions(opts),
+	}
+}
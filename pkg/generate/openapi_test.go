@@ -0,0 +1,127 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/anydotcloud/grm/pkg/path/fieldpath"
+	"github.com/anydotcloud/grm/pkg/types/resource/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anydotcloud/grm-generate/pkg/generate"
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+func TestOpenAPIGenerator(t *testing.T) {
+	require := require.New(t)
+
+	rd := newRepository("Name")
+	tagDef := &model.FieldDefinition{
+		Type:        schema.FieldTypeList,
+		ElementType: schema.FieldTypeStruct,
+		MemberFieldDefinitions: map[string]*model.FieldDefinition{
+			"Key": {
+				Type:       schema.FieldTypeString,
+				IsRequired: true,
+			},
+			"Value": {Type: schema.FieldTypeString},
+		},
+	}
+	rd.AddField(model.NewField(fieldpath.FromString("Tags"), nil, tagDef))
+	rd.AddField(model.NewField(
+		fieldpath.FromString("Tags.Key"), nil,
+		tagDef.MemberFieldDefinitions["Key"],
+	))
+	rd.AddField(model.NewField(
+		fieldpath.FromString("Tags.Value"), nil,
+		tagDef.MemberFieldDefinitions["Value"],
+	))
+	rd.AddField(model.NewField(
+		fieldpath.FromString("CreatedAt"), nil,
+		&model.FieldDefinition{
+			Type:          schema.FieldTypeTime,
+			IsReadOnly:    true,
+			Documentation: "The date and time the repository was created.",
+		},
+	))
+	rd.AddField(model.NewField(
+		fieldpath.FromString("Limits"), nil,
+		&model.FieldDefinition{
+			Type:      schema.FieldTypeMap,
+			KeyType:   schema.FieldTypeString,
+			ValueType: schema.FieldTypeInt,
+		},
+	))
+
+	gen, err := generate.New(generate.TargetOpenAPI)
+	require.Nil(err)
+	files, err := gen.Generate(
+		context.TODO(), []*model.ResourceDefinition{rd},
+	)
+	require.Nil(err)
+	require.Len(files, 1)
+	require.Equal("ecr/repository.json", files[0].Path)
+
+	expect := `{
+  "title": "Repository",
+  "description": "Repository is a resource of the AWS ecr service",
+  "type": "object",
+  "properties": {
+    "CreatedAt": {
+      "description": "The date and time the repository was created.",
+      "type": "string",
+      "format": "date-time",
+      "readOnly": true
+    },
+    "Limits": {
+      "type": "object",
+      "additionalProperties": {
+        "type": "integer",
+        "format": "int64"
+      }
+    },
+    "Name": {
+      "type": "string"
+    },
+    "Tags": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "Key": {
+            "type": "string"
+          },
+          "Value": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "Key"
+        ]
+      }
+    }
+  },
+  "required": [
+    "Name"
+  ]
+}
+`
+	assert.Equal(t, expect, string(files[0].Contents))
+}
+
+func TestNewUnsupportedTarget(t *testing.T) {
+	_, err := generate.New("cobol")
+	require.NotNil(t, err)
+}
@@ -0,0 +1,109 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generate
+
+import (
+	"github.com/anydotcloud/grm-generate/pkg/config"
+)
+
+const (
+	// DefaultPackageBase is the Go import path of the output directory used
+	// when no package base is supplied
+	DefaultPackageBase = "github.com/anydotcloud/grm-generated"
+	// DefaultAPIVersion is the name of the Go package containing the
+	// generated resource types when no API version is supplied
+	DefaultAPIVersion = "v1"
+)
+
+type option struct {
+	cfg          *config.Config
+	templateDirs []string
+	packageBase  string
+	apiVersion   string
+	outputPath   string
+}
+
+// WithConfig uses the supplied Config to render the license header of
+// generated files
+func WithConfig(cfg *config.Config) option {
+	return option{
+		cfg: cfg,
+	}
+}
+
+// WithTemplateDirs instructs the generator to load Go templates from the
+// supplied directories in addition to the DefaultTemplates. Templates in
+// later directories override or extend the templates with the same path in
+// earlier directories and in the DefaultTemplates.
+func WithTemplateDirs(paths ...string) option {
+	return option{
+		templateDirs: paths,
+	}
+}
+
+// WithPackageBase instructs the generator which Go import path corresponds to
+// the output directory. Generated packages import each other using this
+// import path as a prefix.
+func WithPackageBase(packageBase string) option {
+	return option{
+		packageBase: packageBase,
+	}
+}
+
+// WithAPIVersion instructs the generator which Go package name to use for the
+// generated resource types, e.g. "v1"
+func WithAPIVersion(apiVersion string) option {
+	return option{
+		apiVersion: apiVersion,
+	}
+}
+
+// WithOutputPath instructs the generator which directory the generated files
+// will be written to. Generators that preserve state across runs, such as
+// Protocol Buffers field numbers, read the previously generated files from
+// this directory.
+func WithOutputPath(path string) option {
+	return option{
+		outputPath: path,
+	}
+}
+
+// mergeOptions merges any supplied option values with any defaults and returns
+// a single option
+func mergeOptions(opts []option) option {
+	res := option{}
+	for _, opt := range opts {
+		if opt.cfg != nil {
+			res.cfg = opt.cfg
+		}
+		if len(opt.templateDirs) > 0 {
+			res.templateDirs = append(res.templateDirs, opt.templateDirs...)
+		}
+		if opt.packageBase != "" {
+			res.packageBase = opt.packageBase
+		}
+		if opt.apiVersion != "" {
+			res.apiVersion = opt.apiVersion
+		}
+		if opt.outputPath != "" {
+			res.outputPath = opt.outputPath
+		}
+	}
+	// now process the defaults...
+	if res.packageBase == "" {
+		res.packageBase = DefaultPackageBase
+	}
+	if res.apiVersion == "" {
+		res.apiVersion = DefaultAPIVersion
+	}
+	return res
+}
@@ -0,0 +1,509 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/anydotcloud/grm/pkg/names"
+	"github.com/anydotcloud/grm/pkg/types/resource/schema"
+	"github.com/ghodss/yaml"
+	"github.com/samber/lo"
+
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+const (
+	// ProtoFieldNumbersFileName is the name of the file, written next to
+	// each generated .proto file, that records the number assigned to each
+	// message field and enum value so that regeneration never renumbers
+	ProtoFieldNumbersFileName = "field_numbers.yaml"
+)
+
+// protoNumbers is a map, keyed by fully-qualified message or enum name, of
+// the numbers assigned to the message's fields or the enum's values
+type protoNumbers map[string]*protoNumbering
+
+// protoNumbering records the numbers assigned to the fields of a message or
+// the values of an enum. Numbers of removed fields and enum values, and the
+// previous numbers of fields whose type changed, are kept so they are never
+// reused.
+type protoNumbering struct {
+	// Numbers is a map, keyed by field or enum value name, of the assigned
+	// number
+	Numbers map[string]int `json:"numbers"`
+	// Types is a map, keyed by field name, of the type of the field that was
+	// assigned the number
+	Types map[string]string `json:"types,omitempty"`
+	// Retired contains the previous numbers of fields whose type changed
+	Retired []int `json:"retired,omitempty"`
+}
+
+// protoMessage describes a Protocol Buffers message
+type protoMessage struct {
+	Name          string
+	Documentation string
+	Fields        []*protoField
+	// Reserved contains the names and numbers of removed fields
+	Reserved []*protoField
+	Messages []*protoMessage
+	Enums    []*protoEnum
+}
+
+// protoField describes a message field or enum value. A reserved protoField
+// without a name reserves only its number.
+type protoField struct {
+	Name          string
+	Type          string
+	Number        int
+	Documentation string
+}
+
+// protoEnum describes a Protocol Buffers enum
+type protoEnum struct {
+	Name   string
+	Values []*protoField
+	// Reserved contains the names and numbers of removed enum values
+	Reserved []*protoField
+}
+
+// protoFile collects the messages and imports of a single .proto file
+type protoFile struct {
+	numbers  protoNumbers
+	messages []*protoMessage
+	imports  map[string]bool
+}
+
+// protoGenerator writes a Protocol Buffers definition file for each service.
+// It implements the `Generator` interface.
+type protoGenerator struct {
+	opts option
+}
+
+func (g *protoGenerator) Generate(
+	ctx context.Context,
+	rds []*model.ResourceDefinition,
+) ([]*File, error) {
+	// Resources are grouped by service, preserving the order of the supplied
+	// resources
+	services := []string{}
+	byService := map[string][]*model.ResourceDefinition{}
+	for _, rd := range rds {
+		svc := rd.Kind.Service
+		if _, found := byService[svc]; !found {
+			services = append(services, svc)
+		}
+		byService[svc] = append(byService[svc], rd)
+	}
+	header, err := boilerplateHeader(g.opts.cfg.GetBoilerplateConfig())
+	if err != nil {
+		return nil, err
+	}
+	res := []*File{}
+	for _, svc := range services {
+		numbersPath := path.Join(svc, ProtoFieldNumbersFileName)
+		numbers, err := readProtoNumbers(
+			filepath.Join(g.opts.outputPath, numbersPath),
+		)
+		if err != nil {
+			return nil, err
+		}
+		pf := &protoFile{
+			numbers: numbers,
+			imports: map[string]bool{},
+		}
+		svcRDs := byService[svc]
+		for _, rd := range svcRDs {
+			members := map[string]*model.FieldDefinition{}
+			for _, fp := range rd.GetFieldPaths() {
+				if fp.Size() == 1 {
+					members[fp.Back()] = rd.GetField(fp).Definition
+				}
+			}
+			pf.messages = append(pf.messages, pf.newMessage(
+				rd.Kind.Name, rd.Kind.Name,
+				resourceDescription(rd.Kind),
+				members,
+			))
+		}
+		b, err := yaml.Marshal(pf.numbers)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res,
+			&File{
+				Path: path.Join(svc, svc+".proto"),
+				Contents: []byte(pf.render(
+					header,
+					strings.Join([]string{
+						svcRDs[0].Kind.CloudProvider, svc, g.opts.apiVersion,
+					}, "."),
+					path.Join(g.opts.packageBase, svc, "proto"),
+				)),
+			},
+			&File{
+				Path:     numbersPath,
+				Contents: b,
+			},
+		)
+	}
+	return res, nil
+}
+
+// readProtoNumbers returns the field numbers in the supplied field numbers
+// file, or empty field numbers if there is no such file
+func readProtoNumbers(filePath string) (protoNumbers, error) {
+	res := protoNumbers{}
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return res, nil
+		}
+		return nil, err
+	}
+	if err := yaml.Unmarshal(b, &res); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %s", filePath, err)
+	}
+	return res, nil
+}
+
+// newMessage returns a message, with the supplied fully-qualified name, that
+// has a field for each of the supplied member field definitions. Struct
+// members and members with enum values are described by nested messages and
+// enums.
+func (pf *protoFile) newMessage(
+	name string,
+	fullName string,
+	documentation string,
+	members map[string]*model.FieldDefinition,
+) *protoMessage {
+	res := &protoMessage{
+		Name:          name,
+		Documentation: documentation,
+	}
+	memberNames := lo.Keys(members)
+	sort.Strings(memberNames)
+	fieldNames := make([]string, 0, len(memberNames))
+	fieldTypes := map[string]string{}
+	for _, memberName := range memberNames {
+		def := members[memberName]
+		n := names.New(memberName)
+		f := &protoField{
+			Name:          n.Snake,
+			Type:          pf.fieldType(res, fullName, n.Camel, def),
+			Documentation: def.Documentation,
+		}
+		res.Fields = append(res.Fields, f)
+		fieldNames = append(fieldNames, f.Name)
+		fieldTypes[f.Name] = f.Type
+	}
+	numbers, reserved := pf.assignNumbers(fullName, fieldNames, fieldTypes)
+	for _, f := range res.Fields {
+		f.Number = numbers[f.Name]
+	}
+	sort.Slice(res.Fields, func(i, j int) bool {
+		return res.Fields[i].Number < res.Fields[j].Number
+	})
+	res.Reserved = reserved
+	return res
+}
+
+// newEnum returns an enum, with the supplied fully-qualified name, that has a
+// value for each of the supplied enum values along with an unspecified zero
+// value. Enum value names are prefixed with the enum name since enum values
+// share the scope of the enclosing message. An enum value named like the zero
+// value, such as "UNSPECIFIED", is represented by the zero value.
+func (pf *protoFile) newEnum(
+	name string,
+	fullName string,
+	values []string,
+) *protoEnum {
+	prefix := strings.ToUpper(names.New(name).Snake) + "_"
+	zero := prefix + "UNSPECIFIED"
+	res := &protoEnum{
+		Name: name,
+		Values: []*protoField{
+			{Name: zero, Number: 0},
+		},
+	}
+	valueNames := lo.Without(lo.Uniq(lo.Map(values, func(v string, _ int) string {
+		return prefix + protoEnumValueName(v)
+	})), zero)
+	numbers, reserved := pf.assignNumbers(fullName, valueNames, nil)
+	for _, v := range valueNames {
+		res.Values = append(res.Values, &protoField{
+			Name:   v,
+			Number: numbers[v],
+		})
+	}
+	sort.Slice(res.Values, func(i, j int) bool {
+		return res.Values[i].Number < res.Values[j].Number
+	})
+	res.Reserved = reserved
+	return res
+}
+
+// fieldType returns the Protocol Buffers type of a field of the supplied
+// message, adding any nested message or enum describing the field to the
+// message
+func (pf *protoFile) fieldType(
+	msg *protoMessage,
+	msgFullName string,
+	typeName string,
+	def *model.FieldDefinition,
+) string {
+	switch def.Type {
+	case schema.FieldTypeList:
+		return "repeated " + pf.elementType(
+			msg, msgFullName, typeName, def.ElementType, def,
+		)
+	case schema.FieldTypeMap:
+		keyType := "string"
+		if def.KeyType == schema.FieldTypeInt {
+			keyType = "int64"
+		}
+		return fmt.Sprintf(
+			"map<%s, %s>", keyType, pf.elementType(
+				msg, msgFullName, typeName, def.ValueType, def,
+			),
+		)
+	}
+	return pf.elementType(msg, msgFullName, typeName, def.Type, def)
+}
+
+// elementType returns the Protocol Buffers type of a singular field, list
+// element or map value of the supplied field type. The MemberFieldDefinitions
+// and EnumValues of the supplied field definition describe the struct members
+// or enum values of the element.
+func (pf *protoFile) elementType(
+	msg *protoMessage,
+	msgFullName string,
+	typeName string,
+	fieldType schema.FieldType,
+	def *model.FieldDefinition,
+) string {
+	fullName := msgFullName + "." + typeName
+	switch fieldType {
+	case schema.FieldTypeStruct:
+		msg.Messages = append(msg.Messages, pf.newMessage(
+			typeName, fullName, "", def.MemberFieldDefinitions,
+		))
+		return typeName
+	case schema.FieldTypeString:
+		if len(def.EnumValues) == 0 {
+			return "string"
+		}
+		msg.Enums = append(
+			msg.Enums, pf.newEnum(typeName, fullName, def.EnumValues),
+		)
+		return typeName
+	case schema.FieldTypeBool:
+		return "bool"
+	case schema.FieldTypeInt:
+		return "int64"
+	case schema.FieldTypeFloat:
+		return "double"
+	case schema.FieldTypeTime:
+		pf.imports["google/protobuf/timestamp.proto"] = true
+		return "google.protobuf.Timestamp"
+	case schema.FieldTypeList:
+		// Repeated fields and maps cannot be nested so nested lists and
+		// maps are described by the well-known dynamic types
+		pf.imports["google/protobuf/struct.proto"] = true
+		return "google.protobuf.ListValue"
+	case schema.FieldTypeMap:
+		pf.imports["google/protobuf/struct.proto"] = true
+		return "google.protobuf.Struct"
+	}
+	pf.imports["google/protobuf/struct.proto"] = true
+	return "google.protobuf.Value"
+}
+
+// assignNumbers returns a map, keyed by the supplied field or enum value
+// names, of the number of each field or enum value of the message or enum
+// with the supplied fully-qualified name, along with the reserved numbers and
+// names of the previously numbered fields or enum values that no longer
+// exist. The supplied map contains the type of each field and is nil for enum
+// values. Previously assigned numbers are kept and new names are numbered, in
+// order, after the highest previously assigned number. A field whose type
+// changed is numbered as a new field, since the new type may
+// not be compatible with the old type on the wire, and its previous number is
+// reserved.
+func (pf *protoFile) assignNumbers(
+	fullName string,
+	fieldNames []string,
+	fieldTypes map[string]string,
+) (map[string]int, []*protoField) {
+	numbering, found := pf.numbers[fullName]
+	if !found {
+		numbering = &protoNumbering{}
+		pf.numbers[fullName] = numbering
+	}
+	if numbering.Numbers == nil {
+		numbering.Numbers = map[string]int{}
+	}
+	numbers := numbering.Numbers
+	highest := lo.Max(numbering.Retired)
+	for _, n := range numbers {
+		if n > highest {
+			highest = n
+		}
+	}
+	for _, name := range fieldNames {
+		typ := fieldTypes[name]
+		n, found := numbers[name]
+		if found && typ != numbering.Types[name] {
+			numbering.Retired = append(numbering.Retired, n)
+			found = false
+		}
+		if !found {
+			highest++
+			numbers[name] = highest
+		}
+		if typ != "" {
+			if numbering.Types == nil {
+				numbering.Types = map[string]string{}
+			}
+			numbering.Types[name] = typ
+		}
+	}
+	reserved := lo.Map(numbering.Retired, func(n int, _ int) *protoField {
+		return &protoField{Number: n}
+	})
+	for name, n := range numbers {
+		if !lo.Contains(fieldNames, name) {
+			reserved = append(reserved, &protoField{Name: name, Number: n})
+		}
+	}
+	sort.Slice(reserved, func(i, j int) bool {
+		return reserved[i].Number < reserved[j].Number
+	})
+	return numbers, reserved
+}
+
+// render returns the contents of the .proto file, beginning with the
+// supplied license header, if any
+func (pf *protoFile) render(
+	header string,
+	pkg string,
+	goPackage string,
+) string {
+	var b strings.Builder
+	if header != "" {
+		b.WriteString(header + "\n\n")
+	}
+	b.WriteString("// Code generated by grm-generate. DO NOT EDIT.\n\n")
+	b.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(&b, "package %s;\n\n", pkg)
+	imports := lo.Keys(pf.imports)
+	sort.Strings(imports)
+	for _, imp := range imports {
+		fmt.Fprintf(&b, "import %q;\n", imp)
+	}
+	if len(imports) > 0 {
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "option go_package = %q;\n", goPackage)
+	for _, msg := range pf.messages {
+		b.WriteString("\n")
+		writeProtoMessage(&b, msg, "")
+	}
+	return b.String()
+}
+
+// writeProtoMessage writes the supplied message, and its nested messages and
+// enums, at the supplied indentation
+func writeProtoMessage(b *strings.Builder, msg *protoMessage, indent string) {
+	writeProtoComment(b, msg.Documentation, indent)
+	fmt.Fprintf(b, "%smessage %s {\n", indent, msg.Name)
+	inner := indent + "  "
+	for _, enum := range msg.Enums {
+		fmt.Fprintf(b, "%senum %s {\n", inner, enum.Name)
+		for _, v := range enum.Values {
+			fmt.Fprintf(b, "%s  %s = %d;\n", inner, v.Name, v.Number)
+		}
+		writeProtoReserved(b, enum.Reserved, inner+"  ")
+		fmt.Fprintf(b, "%s}\n\n", inner)
+	}
+	for _, nested := range msg.Messages {
+		writeProtoMessage(b, nested, inner)
+		b.WriteString("\n")
+	}
+	for _, f := range msg.Fields {
+		writeProtoComment(b, f.Documentation, inner)
+		fmt.Fprintf(b, "%s%s %s = %d;\n", inner, f.Type, f.Name, f.Number)
+	}
+	writeProtoReserved(b, msg.Reserved, inner)
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+// writeProtoReserved writes reserved statements for the numbers and names of
+// the supplied removed fields or enum values and for the numbers of the
+// supplied unnamed reserved fields
+func writeProtoReserved(
+	b *strings.Builder,
+	reserved []*protoField,
+	indent string,
+) {
+	if len(reserved) == 0 {
+		return
+	}
+	numbers := lo.Map(reserved, func(f *protoField, _ int) string {
+		return fmt.Sprintf("%d", f.Number)
+	})
+	quoted := lo.FilterMap(reserved, func(f *protoField, _ int) (string, bool) {
+		return fmt.Sprintf("%q", f.Name), f.Name != ""
+	})
+	fmt.Fprintf(b, "%sreserved %s;\n", indent, strings.Join(numbers, ", "))
+	if len(quoted) > 0 {
+		fmt.Fprintf(b, "%sreserved %s;\n", indent, strings.Join(quoted, ", "))
+	}
+}
+
+// writeProtoComment writes the supplied text as a comment at the supplied
+// indentation
+func writeProtoComment(b *strings.Builder, text string, indent string) {
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(goComment(text), "\n") {
+		b.WriteString(indent + line + "\n")
+	}
+}
+
+// protoEnumValueName returns the supplied enum value as an upper-case
+// identifier, e.g. "US_EAST_1" for "us-east-1"
+func protoEnumValueName(v string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.ToUpper(v))
+}
+
+// NewProtoGenerator returns a new Generator that writes a Protocol Buffers
+// definition file, along with a file recording the assigned field numbers,
+// for each service
+func NewProtoGenerator(
+	opts ...option,
+) Generator {
+	return &protoGenerator{
+		opts: mergeOptions(opts),
+	}
+}
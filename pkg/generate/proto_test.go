@@ -0,0 +1,210 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/anydotcloud/grm/pkg/path/fieldpath"
+	"github.com/anydotcloud/grm/pkg/types/resource/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anydotcloud/grm-generate/pkg/generate"
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+func TestProtoGenerator(t *testing.T) {
+	require := require.New(t)
+
+	rd := newRepository("Name")
+	rd.AddField(model.NewField(
+		fieldpath.FromString("ImageTagMutability"), nil,
+		&model.FieldDefinition{
+			Type:       schema.FieldTypeString,
+			EnumValues: []string{"MUTABLE", "IMMUTABLE"},
+		},
+	))
+	encDef := &model.FieldDefinition{
+		Type: schema.FieldTypeStruct,
+		MemberFieldDefinitions: map[string]*model.FieldDefinition{
+			"KMSKey": {
+				Type:          schema.FieldTypeString,
+				Documentation: "The KMS key to use.",
+			},
+		},
+	}
+	rd.AddField(model.NewField(
+		fieldpath.FromString("EncryptionConfiguration"), nil, encDef,
+	))
+	rd.AddField(model.NewField(
+		fieldpath.FromString("EncryptionConfiguration.KMSKey"), nil,
+		encDef.MemberFieldDefinitions["KMSKey"],
+	))
+	rd.AddField(model.NewField(
+		fieldpath.FromString("CreatedAt"), nil,
+		&model.FieldDefinition{Type: schema.FieldTypeTime},
+	))
+	rd.AddField(model.NewField(
+		fieldpath.FromString("Labels"), nil,
+		&model.FieldDefinition{
+			Type:      schema.FieldTypeMap,
+			KeyType:   schema.FieldTypeString,
+			ValueType: schema.FieldTypeString,
+		},
+	))
+
+	dir := t.TempDir()
+	gen, err := generate.New(
+		generate.TargetProto, generate.WithOutputPath(dir),
+	)
+	require.Nil(err)
+	files, err := gen.Generate(
+		context.TODO(), []*model.ResourceDefinition{rd},
+	)
+	require.Nil(err)
+	require.Len(files, 2)
+	require.Equal("ecr/ecr.proto", files[0].Path)
+	require.Equal("ecr/"+generate.ProtoFieldNumbersFileName, files[1].Path)
+
+	expect := `// Code generated by grm-generate. DO NOT EDIT.
+
+syntax = "proto3";
+
+package aws.ecr.v1;
+
+import "google/protobuf/timestamp.proto";
+
+option go_package = "github.com/anydotcloud/grm-generated/ecr/proto";
+
+// Repository is a resource of the AWS ecr service
+message Repository {
+  enum ImageTagMutability {
+    IMAGE_TAG_MUTABILITY_UNSPECIFIED = 0;
+    IMAGE_TAG_MUTABILITY_MUTABLE = 1;
+    IMAGE_TAG_MUTABILITY_IMMUTABLE = 2;
+  }
+
+  message EncryptionConfiguration {
+    // The KMS key to use.
+    string kms_key = 1;
+  }
+
+  google.protobuf.Timestamp created_at = 1;
+  EncryptionConfiguration encryption_configuration = 2;
+  ImageTagMutability image_tag_mutability = 3;
+  map<string, string> labels = 4;
+  string name = 5;
+}
+`
+	assert.Equal(t, expect, string(files[0].Contents))
+
+	// Removing fields and enum values and adding new ones must never
+	// renumber the remaining fields and enum values
+	require.Nil(generate.WriteFiles(dir, files))
+	rd = newRepository("Name")
+	rd.AddField(model.NewField(
+		fieldpath.FromString("ImageTagMutability"), nil,
+		&model.FieldDefinition{
+			Type:       schema.FieldTypeString,
+			EnumValues: []string{"IMMUTABLE", "IMMUTABLE_WITH_EXCLUSION"},
+		},
+	))
+	rd.AddField(model.NewField(
+		fieldpath.FromString("ARN"), nil,
+		&model.FieldDefinition{Type: schema.FieldTypeString},
+	))
+	files, err = gen.Generate(
+		context.TODO(), []*model.ResourceDefinition{rd},
+	)
+	require.Nil(err)
+
+	expect = `// Code generated by grm-generate. DO NOT EDIT.
+
+syntax = "proto3";
+
+package aws.ecr.v1;
+
+option go_package = "github.com/anydotcloud/grm-generated/ecr/proto";
+
+// Repository is a resource of the AWS ecr service
+message Repository {
+  enum ImageTagMutability {
+    IMAGE_TAG_MUTABILITY_UNSPECIFIED = 0;
+    IMAGE_TAG_MUTABILITY_IMMUTABLE = 2;
+    IMAGE_TAG_MUTABILITY_IMMUTABLE_WITH_EXCLUSION = 3;
+    reserved 1;
+    reserved "IMAGE_TAG_MUTABILITY_MUTABLE";
+  }
+
+  ImageTagMutability image_tag_mutability = 3;
+  string name = 5;
+  string arn = 6;
+  reserved 1, 2, 4;
+  reserved "created_at", "encryption_configuration", "labels";
+}
+`
+	assert.Equal(t, expect, string(files[0].Contents))
+
+	// Changing a field's type numbers the field as a new field and reserves
+	// its previous number. An enum value named like the zero value is
+	// represented by the zero value.
+	require.Nil(generate.WriteFiles(dir, files))
+	rd = newRepository("Name")
+	rd.GetField(fieldpath.FromString("Name")).Definition.Type =
+		schema.FieldTypeInt
+	rd.AddField(model.NewField(
+		fieldpath.FromString("ImageTagMutability"), nil,
+		&model.FieldDefinition{
+			Type: schema.FieldTypeString,
+			EnumValues: []string{
+				"UNSPECIFIED", "IMMUTABLE", "IMMUTABLE_WITH_EXCLUSION",
+			},
+		},
+	))
+	rd.AddField(model.NewField(
+		fieldpath.FromString("ARN"), nil,
+		&model.FieldDefinition{Type: schema.FieldTypeString},
+	))
+	files, err = gen.Generate(
+		context.TODO(), []*model.ResourceDefinition{rd},
+	)
+	require.Nil(err)
+
+	expect = `// Code generated by grm-generate. DO NOT EDIT.
+
+syntax = "proto3";
+
+package aws.ecr.v1;
+
+option go_package = "github.com/anydotcloud/grm-generated/ecr/proto";
+
+// Repository is a resource of the AWS ecr service
+message Repository {
+  enum ImageTagMutability {
+    IMAGE_TAG_MUTABILITY_UNSPECIFIED = 0;
+    IMAGE_TAG_MUTABILITY_IMMUTABLE = 2;
+    IMAGE_TAG_MUTABILITY_IMMUTABLE_WITH_EXCLUSION = 3;
+    reserved 1;
+    reserved "IMAGE_TAG_MUTABILITY_MUTABLE";
+  }
+
+  ImageTagMutability image_tag_mutability = 3;
+  string arn = 6;
+  int64 name = 7;
+  reserved 1, 2, 4, 5;
+  reserved "created_at", "encryption_configuration", "labels";
+}
+`
+	assert.Equal(t, expect, string(files[0].Contents))
+}
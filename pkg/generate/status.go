@@ -0,0 +1,169 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/anydotcloud/grm/pkg/names"
+	"github.com/anydotcloud/grm/pkg/path/fieldpath"
+	"github.com/anydotcloud/grm/pkg/types/resource/schema"
+	"github.com/samber/lo"
+
+	"github.com/anydotcloud/grm-generate/pkg/config"
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+var (
+	// inferredReadyStatuses contains the upper-cased enum values that
+	// indicate a resource is ready when inferring status rules
+	inferredReadyStatuses = []string{
+		"ACTIVE", "AVAILABLE", "READY", "RUNNING", "ENABLED", "ISSUED",
+		"IN_SERVICE", "INSERVICE", "COMPLETED", "SUCCEEDED", "CREATED",
+	}
+	// inferredFailedStatusParts contains the upper-cased strings that
+	// indicate a resource has failed when contained in an enum value when
+	// inferring status rules
+	inferredFailedStatusParts = []string{"FAIL", "ERROR", "INCOMPATIBLE"}
+	// inferredTerminalStatuses contains the upper-cased enum values that
+	// indicate a resource may no longer be modified when inferring status
+	// rules
+	inferredTerminalStatuses = []string{
+		"DELETING", "DELETED", "ARCHIVING", "ARCHIVED", "TERMINATING",
+		"TERMINATED",
+	}
+)
+
+// statusData describes how the generated resource determines whether it is
+// ready, has failed or may no longer be modified. A zero statusData means the
+// resource has no status field.
+type statusData struct {
+	// Path is the stringified field path of the status field
+	Path string
+	// Guards contains the Go expressions, in order, that must not be nil
+	// before the status field's value can be read
+	Guards []string
+	// Value is the Go expression for the pointer to the status field's
+	// value
+	Value string
+	// ReadyCases contains the comma-separated, quoted status values that
+	// indicate the resource is ready
+	ReadyCases string
+	// FailedCases contains the comma-separated, quoted status values that
+	// indicate the resource has failed
+	FailedCases string
+	// TerminalCases contains the comma-separated, quoted status values that
+	// indicate the resource may no longer be modified
+	TerminalCases string
+}
+
+// newStatusData returns the status rules of the supplied resource. The rules
+// are read from the resource's status configuration or, if there is none,
+// inferred from a top-level, status-like enum field.
+func newStatusData(rd *model.ResourceDefinition) (statusData, error) {
+	sc := rd.Config.GetStatusConfig()
+	if sc == nil {
+		sc = inferStatusConfig(rd)
+		if sc == nil {
+			return statusData{}, nil
+		}
+	}
+	fp := fieldpath.FromString(sc.Path)
+	res := statusData{
+		Path:          fp.String(),
+		ReadyCases:    quoteCases(sc.ReadyValues),
+		FailedCases:   quoteCases(sc.FailedValues),
+		TerminalCases: quoteCases(sc.TerminalValues),
+	}
+	// Each containing field of the status field must be a struct so that
+	// the status field's value can be reached with the typed getters
+	expr := ""
+	for x := 0; x < fp.Size(); x++ {
+		f := rd.GetField(fp.CopyAt(x))
+		if f == nil {
+			return statusData{}, fmt.Errorf(
+				"status field %s of resource %s does not exist",
+				sc.Path, rd.Kind.Name,
+			)
+		}
+		name := names.New(fp.At(x)).Camel
+		if x == 0 {
+			expr = "r.Get" + name + "()"
+		} else {
+			expr += "." + name
+		}
+		res.Guards = append(res.Guards, expr)
+		isLast := x == fp.Size()-1
+		if !isLast && f.Definition.Type != schema.FieldTypeStruct {
+			return statusData{}, fmt.Errorf(
+				"status field %s of resource %s is not contained in "+
+					"struct fields", sc.Path, rd.Kind.Name,
+			)
+		}
+		if isLast && f.Definition.Type != schema.FieldTypeString {
+			return statusData{}, fmt.Errorf(
+				"status field %s of resource %s is not a string field",
+				sc.Path, rd.Kind.Name,
+			)
+		}
+	}
+	res.Value = expr
+	return res, nil
+}
+
+// inferStatusConfig returns status rules inferred from the first top-level
+// enum field whose name ends with "Status" or "State" and that has at least
+// one enum value indicating readiness, or nil if there is no such field
+func inferStatusConfig(rd *model.ResourceDefinition) *config.StatusConfig {
+	for _, fp := range rd.GetFieldPaths() {
+		if fp.Size() != 1 {
+			continue
+		}
+		lower := strings.ToLower(fp.Back())
+		if !strings.HasSuffix(lower, "status") &&
+			!strings.HasSuffix(lower, "state") {
+			continue
+		}
+		def := rd.GetField(fp).Definition
+		if def.Type != schema.FieldTypeString || len(def.EnumValues) == 0 {
+			continue
+		}
+		res := &config.StatusConfig{Path: fp.String()}
+		for _, v := range def.EnumValues {
+			upper := strings.ToUpper(strings.ReplaceAll(v, "-", "_"))
+			switch {
+			case lo.Contains(inferredReadyStatuses, upper):
+				res.ReadyValues = append(res.ReadyValues, v)
+			case lo.Contains(inferredTerminalStatuses, upper):
+				res.TerminalValues = append(res.TerminalValues, v)
+			case lo.SomeBy(inferredFailedStatusParts, func(part string) bool {
+				return strings.Contains(upper, part)
+			}):
+				res.FailedValues = append(res.FailedValues, v)
+			}
+		}
+		if len(res.ReadyValues) > 0 {
+			return res
+		}
+	}
+	return nil
+}
+
+// quoteCases returns the supplied values as a comma-separated list of quoted
+// Go strings
+func quoteCases(values []string) string {
+	return strings.Join(lo.Map(values, func(v string, _ int) string {
+		return strconv.Quote(v)
+	}), ", ")
+}
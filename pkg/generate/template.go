@@ -0,0 +1,103 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+
+	"github.com/anydotcloud/grm-generate/templates"
+)
+
+const (
+	// TemplateHooksPattern matches the paths, relative to a template
+	// directory, of the templates that fill the hook points of the Go
+	// templates. Hook templates are parsed along with every Go template and
+	// are typically made up of `{{ define "<hook name>" }}` actions.
+	TemplateHooksPattern = "hooks/*.tpl"
+)
+
+// layeredFS is an fs.FS made up of layers of other fs.FS. A file is read from
+// the last layer that contains the file, so that later layers override files
+// in earlier layers. Directory listings contain the entries of every layer.
+type layeredFS []fs.FS
+
+func (l layeredFS) Open(name string) (fs.File, error) {
+	for x := len(l) - 1; x >= 0; x-- {
+		f, err := l[x].Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+func (l layeredFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	// byName is a map, keyed by entry name, of the directory entries of the
+	// last layer that contains an entry with that name
+	byName := map[string]fs.DirEntry{}
+	found := false
+	for _, layer := range l {
+		entries, err := fs.ReadDir(layer, name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+		for _, entry := range entries {
+			byName[entry.Name()] = entry
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	res := make([]fs.DirEntry, 0, len(byName))
+	for _, entry := range byName {
+		res = append(res, entry)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name() < res[j].Name()
+	})
+	return res, nil
+}
+
+// DefaultTemplates is the fs.FS containing the default Go templates, which
+// are embedded in the binary
+var DefaultTemplates fs.FS = templates.FS
+
+// NewTemplateFS returns an fs.FS that reads templates from the supplied
+// template directories on top of the DefaultTemplates. Templates in later
+// directories override templates with the same path in earlier directories
+// and in the DefaultTemplates. Returns an error if any of the supplied paths
+// is not a directory.
+func NewTemplateFS(dirs ...string) (fs.FS, error) {
+	res := layeredFS{DefaultTemplates}
+	for _, dir := range dirs {
+		fi, err := os.Stat(dir)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read template directory: %v", err)
+		}
+		if !fi.IsDir() {
+			return nil, fmt.Errorf("template directory %s is not a directory", dir)
+		}
+		res = append(res, os.DirFS(dir))
+	}
+	return res, nil
+}
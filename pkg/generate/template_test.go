@@ -0,0 +1,148 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generate_test
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anydotcloud/grm-generate/pkg/generate"
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+// writeFiles writes the supplied map, keyed by relative path, of file
+// contents into the supplied directory
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for path, contents := range files {
+		fp := filepath.Join(dir, path)
+		require.Nil(t, os.MkdirAll(filepath.Dir(fp), 0o755))
+		require.Nil(t, os.WriteFile(fp, []byte(contents), 0o644))
+	}
+}
+
+func TestNewTemplateFS(t *testing.T) {
+	require := require.New(t)
+
+	base := t.TempDir()
+	overlay := t.TempDir()
+	writeFiles(t, base, map[string]string{
+		"a.tpl":     "base a",
+		"dir/b.tpl": "base b",
+	})
+	writeFiles(t, overlay, map[string]string{
+		"a.tpl":     "overlay a",
+		"dir/c.tpl": "overlay c",
+	})
+
+	fsys, err := generate.NewTemplateFS(base, overlay)
+	require.Nil(err)
+
+	tests := []struct {
+		name   string
+		path   string
+		expErr bool
+		exp    string
+	}{
+		{
+			name: "overridden file",
+			path: "a.tpl",
+			exp:  "overlay a",
+		},
+		{
+			name: "file only in base",
+			path: "dir/b.tpl",
+			exp:  "base b",
+		},
+		{
+			name: "file only in overlay",
+			path: "dir/c.tpl",
+			exp:  "overlay c",
+		},
+		{
+			name:   "file in neither",
+			path:   "dir/d.tpl",
+			expErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := fs.ReadFile(fsys, tt.path)
+			if tt.expErr {
+				assert.ErrorIs(t, err, fs.ErrNotExist)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.exp, string(b))
+		})
+	}
+
+	matches, err := fs.Glob(fsys, "dir/*.tpl")
+	require.Nil(err)
+	require.Equal([]string{"dir/b.tpl", "dir/c.tpl"}, matches)
+
+	_, err = generate.NewTemplateFS(base, filepath.Join(base, "missing"))
+	require.NotNil(err)
+}
+
+func TestGoGeneratorTemplateOverrides(t *testing.T) {
+	require := require.New(t)
+
+	overlay := t.TempDir()
+	writeFiles(t, overlay, map[string]string{
+		"resource/schema/kind.go.tpl": `{{- template "boilerplate" }}
+
+package schema
+
+// Overridden is defined by an overriding template
+const Overridden = "{{ .Name }}"
+`,
+		"hooks/resource.tpl": `{{- define "resource.extra_fields" }}
+    extra string
+{{- end }}
+{{- define "resource.extra_methods" }}
+
+// Extra is defined by a hook
+func (r *{{ .Kind.Name }}) Extra() string {
+    return r.extra
+}
+{{- end }}`,
+	})
+
+	gen := generate.NewGoGenerator(
+		generate.WithTemplateDirs(overlay),
+	)
+	files, err := gen.Generate(
+		context.TODO(),
+		[]*model.ResourceDefinition{newRepository("Name")},
+	)
+	require.Nil(err)
+
+	contents := map[string]string{}
+	for _, f := range files {
+		contents[f.Path] = string(f.Contents)
+	}
+	require.Contains(
+		contents["ecr/repository/schema/kind.go"],
+		`const Overridden = "Repository"`,
+	)
+	resource := contents["ecr/repository/v1/resource.go"]
+	require.Contains(resource, "errors []error\n\textra  string\n}")
+	require.Contains(resource, "func (r *Repository) Extra() string {")
+	// Hooks that are not filled render nothing
+	require.NotContains(contents["ecr/repository/v1/types.go"], "extra")
+}
@@ -21,10 +21,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sort"
 )
 
 type Repository = gogit.Repository
 
+type Reference = gogitplumbing.Reference
+
 var Open = gogit.PlainOpen
 
 // getRepositoryTagRef returns the git reference (commit hash) of a given tag.
@@ -96,6 +99,75 @@ func CheckoutTag(
 	return err
 }
 
+// CheckoutRef checkouts the branch or commit that the supplied reference,
+// such as the one returned by Repository.Head, refers to.
+//
+// Calling this function is equivalent to executing `git checkout $branch` or
+// `git checkout $commit`
+func CheckoutRef(
+	ctx context.Context,
+	repo *Repository,
+	ref *Reference,
+) error {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	opts := &gogit.CheckoutOptions{}
+	if ref.Name().IsBranch() {
+		opts.Branch = ref.Name()
+	} else {
+		opts.Hash = ref.Hash()
+	}
+	return wt.Checkout(opts)
+}
+
+// HeadCommit returns the hash of the commit that is currently checked out in
+// the repository.
+//
+// Calling this function is equivalent to executing `git rev-parse HEAD`
+func HeadCommit(repo *Repository) (string, error) {
+	ref, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+	return ref.Hash().String(), nil
+}
+
+// TagsAtCommit returns the sorted names of the repository's tags that point
+// at the supplied commit hash. Both lightweight and annotated tags are
+// returned.
+//
+// Calling this function is equivalent to executing `git tag --points-at
+// $commit`
+func TagsAtCommit(repo *Repository, commit string) ([]string, error) {
+	tagRefs, err := repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+	res := []string{}
+	for {
+		tagRef, err := tagRefs.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error finding tag reference: %v", err)
+		}
+		target := tagRef.Hash()
+		// Annotated tags point at a tag object that in turn points at the
+		// commit
+		if tagObj, err := repo.TagObject(target); err == nil {
+			target = tagObj.Target
+		}
+		if target.String() == commit {
+			res = append(res, tagRef.Name().Short())
+		}
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
 // Clone clones a git repository into a given directory and returns a
 // Repository object that can be used to manipulate that clone'd repo.
 //
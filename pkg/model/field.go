@@ -21,11 +21,11 @@ import (
 type Field struct {
 	// Path is a "field path" that indicates where the field's value can be
 	// found within the Resource.
-	Path *fieldpath.Path `json:"-"` // ignored in JSON as map keys are paths
+	Path *fieldpath.Path `json:"path"`
 	// Config contains the configuration options for this field
-	Config *config.FieldConfig `json:"-"`
+	Config *config.FieldConfig `json:"config,omitempty"`
 	// Definition contains metadata about the field's type
-	Definition *FieldDefinition
+	Definition *FieldDefinition `json:"definition"`
 }
 
 // Names returns the set of normalized name variations for the field
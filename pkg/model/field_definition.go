@@ -12,6 +12,8 @@
 package model
 
 import (
+	"fmt"
+
 	"github.com/anydotcloud/grm/pkg/types/resource/schema"
 )
 
@@ -54,6 +56,15 @@ type FieldDefinition struct {
 	IsLateInitialized bool `json:"is_late_initialized,omitempty"`
 	// IsSecret is true if the field contains secret information
 	IsSecret bool `json:"is_secret,omitempty"`
+	// IsSet is true if the order of a list field's elements is not
+	// significant
+	IsSet bool `json:"is_set,omitempty"`
+	// EnumValues contains the allowed values of a string field, or of a list
+	// field's elements or a map field's values, when the values are
+	// restricted to a fixed set
+	EnumValues []string `json:"enum_values,omitempty"`
+	// Documentation contains a plain-text description of the field, if any
+	Documentation string `json:"documentation,omitempty"`
 	// References contains the Kind for a referred type if the field contains a
 	// reference to another resource, or nil otherwise.
 	//
@@ -64,3 +75,16 @@ type FieldDefinition struct {
 	// containing "ec2.aws/Subnet".
 	References *Kind `json:"references,omitempty"`
 }
+
+// TypeString returns a human-readable description of the field's type,
+// including any list element type or map key and value types, e.g.
+// "[]string" or "map[string]int".
+func (d *FieldDefinition) TypeString() string {
+	switch d.Type {
+	case schema.FieldTypeList:
+		return "[]" + d.ElementType.String()
+	case schema.FieldTypeMap:
+		return fmt.Sprintf("map[%s]%s", d.KeyType.String(), d.ValueType.String())
+	}
+	return d.Type.String()
+}
@@ -19,21 +19,21 @@ import (
 type Kind struct {
 	// CloudProvider contains the short name of the cloud provider exposing
 	// this type of Resource
-	CloudProvider string
+	CloudProvider string `json:"cloud_provider"`
 	// ServiceName contains the short name of the service exposing this type of
 	// Resource
-	Service string
+	Service string `json:"service"`
 	// Name contains the camel-cased name of the resource (i.e. the Kind, in
 	// Kubernetes speak).
 	//
 	// Note that the combination of CloudProvider, Service and Name is a unique
 	// identifier for this type of Resource.
-	Name string
+	Name string `json:"name"`
 	// PluralName contains the camel-cased name of the pluralized resource.
 	//
 	// Note that the combination of CloudProvider, Service and PluralName is a
 	// unique identifier for this type of Resource.
-	PluralName string
+	PluralName string `json:"plural_name"`
 }
 
 // NewKind returns a new Kind that describes the type of a single top-level
@@ -52,3 +52,15 @@ func NewKind(
 		PluralName:    pluralName,
 	}
 }
+
+// Less returns true if the Kind sorts before the supplied other Kind. Kinds
+// are ordered by CloudProvider, then Service, then Name.
+func (k Kind) Less(other Kind) bool {
+	if k.CloudProvider != other.CloudProvider {
+		return k.CloudProvider < other.CloudProvider
+	}
+	if k.Service != other.Service {
+		return k.Service < other.Service
+	}
+	return k.Name < other.Name
+}
@@ -25,12 +25,18 @@ import (
 // API
 type ResourceDefinition struct {
 	// Config contains the resource-specific configuration options
-	Config *config.ResourceConfig `json:"-"`
+	Config *config.ResourceConfig `json:"config,omitempty"`
 	// Kind is the type of Resource
-	Kind Kind
+	Kind Kind `json:"kind"`
 	// Fields is a map, keyed by the **field path**, of Field objects
 	// representing a field in the Resource.
-	Fields map[string]*Field
+	Fields map[string]*Field `json:"fields"`
+	// Children contains the Kinds of child resources that are added to or
+	// removed from this Resource using one of this Resource's API operations.
+	Children []Kind `json:"children,omitempty"`
+	// Ignore determines which of the Resource's field paths are skipped when
+	// its fields are discovered. It is not serialized.
+	Ignore *config.IgnoreConfig `json:"-"`
 }
 
 // FieldPaths returns a sorted list of field paths for this resource.
@@ -73,3 +79,11 @@ func NewResourceDefinition(
 		Fields: map[string]*Field{},
 	}
 }
+
+// SortResourceDefinitions sorts the supplied slice of ResourceDefinitions by
+// their Kind
+func SortResourceDefinitions(rds []*ResourceDefinition) {
+	sort.SliceStable(rds, func(i, j int) bool {
+		return rds[i].Kind.Less(rds[j].Kind)
+	})
+}
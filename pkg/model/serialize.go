@@ -0,0 +1,113 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/anydotcloud/grm/pkg/path/fieldpath"
+	"github.com/ghodss/yaml"
+)
+
+const (
+	// DocumentVersion is the version of the on-disk format for
+	// ResourceDefinitions written by MarshalYAML and MarshalJSON. It must be
+	// changed whenever a change to the model would prevent a document from
+	// being read back by an older or newer version of grm-generate.
+	DocumentVersion = "v1"
+)
+
+// Document is the on-disk format for a set of ResourceDefinitions
+type Document struct {
+	// Version is the version of the on-disk format
+	Version string `json:"version"`
+	// Resources contains the ResourceDefinitions in the document
+	Resources []*ResourceDefinition `json:"resources"`
+}
+
+// MarshalYAML returns a versioned YAML document containing the supplied
+// ResourceDefinitions. Map keys, including field paths, are written in sorted
+// order.
+func MarshalYAML(rds []*ResourceDefinition) ([]byte, error) {
+	return yaml.Marshal(newDocument(rds))
+}
+
+// MarshalJSON returns a versioned, indented JSON document containing the
+// supplied ResourceDefinitions. Map keys, including field paths, are written
+// in sorted order.
+func MarshalJSON(rds []*ResourceDefinition) ([]byte, error) {
+	b, err := json.MarshalIndent(newDocument(rds), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// Unmarshal returns the ResourceDefinitions contained in the supplied YAML or
+// JSON document that was written by MarshalYAML or MarshalJSON. An error is
+// returned if the document's version is not supported.
+func Unmarshal(b []byte) ([]*ResourceDefinition, error) {
+	doc := &Document{}
+	if err := yaml.Unmarshal(b, doc); err != nil {
+		return nil, err
+	}
+	if doc.Version == "" {
+		return nil, fmt.Errorf(
+			"document has no version. Expected version %s",
+			DocumentVersion,
+		)
+	}
+	if doc.Version != DocumentVersion {
+		return nil, fmt.Errorf(
+			"unsupported document version %s. Expected version %s",
+			doc.Version, DocumentVersion,
+		)
+	}
+	for _, rd := range doc.Resources {
+		if rd.Fields == nil {
+			rd.Fields = map[string]*Field{}
+		}
+		for pathStr, f := range rd.Fields {
+			if f == nil || f.Definition == nil {
+				return nil, fmt.Errorf(
+					"resource %s field %s has no definition",
+					rd.Kind.Name, pathStr,
+				)
+			}
+			// The Fields map is keyed by field path, so the path attribute
+			// of a Field may be omitted.
+			if f.Path == nil || f.Path.Empty() {
+				f.Path = fieldpath.FromString(pathStr)
+			}
+			if f.Path.String() != pathStr {
+				return nil, fmt.Errorf(
+					"resource %s field %s has mismatched path %s",
+					rd.Kind.Name, pathStr, f.Path,
+				)
+			}
+		}
+	}
+	return doc.Resources, nil
+}
+
+// newDocument returns a Document for the current DocumentVersion containing
+// the supplied ResourceDefinitions
+func newDocument(rds []*ResourceDefinition) *Document {
+	if rds == nil {
+		rds = []*ResourceDefinition{}
+	}
+	return &Document{
+		Version:   DocumentVersion,
+		Resources: rds,
+	}
+}
@@ -0,0 +1,169 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/anydotcloud/grm/pkg/path/fieldpath"
+	"github.com/anydotcloud/grm/pkg/types/resource/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anydotcloud/grm-generate/pkg/config"
+	"github.com/anydotcloud/grm-generate/pkg/discover/aws/testutil"
+	"github.com/anydotcloud/grm-generate/pkg/model"
+)
+
+var (
+	// ecrConfig makes the resources discovered in the ECR testdata API model
+	// use the attributes of the model that are not inferred from the API
+	// model alone. Together with the resources discovered in the DynamoDB
+	// testdata API model, the resources use every attribute of the model: the
+	// ECR Repository resource has renamed, secret, read-only and nested
+	// fields and a child resource, and the DynamoDB Backup resource's
+	// TableName field refers to the Table resource.
+	ecrConfig = config.New(
+		config.WithYAML(`
+resources:
+  Repository:
+    fields:
+      Name:
+        renames:
+          - RepositoryName
+      EncryptionConfiguration.KMSKey:
+        is_secret: true
+      RegistryID:
+        is_read_only: true
+    aws:
+      operations:
+        - type: add_child
+          id: PutLifecyclePolicy
+`,
+		),
+	)
+)
+
+func TestMarshalUnmarshal_RoundTrip(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	exp := append(
+		testutil.DiscoverResources(t, config.New(), "dynamodb"),
+		testutil.DiscoverResources(t, ecrConfig, "ecr")...,
+	)
+	require.Len(exp, 5)
+	backup, repo := exp[0], exp[4]
+	require.NotNil(
+		backup.GetField(fieldpath.FromString("TableName")).Definition.References,
+	)
+	require.NotEmpty(repo.Children)
+	require.NotNil(repo.GetField(fieldpath.FromString("Name")))
+
+	for _, marshal := range []func(
+		[]*model.ResourceDefinition,
+	) ([]byte, error){
+		model.MarshalYAML,
+		model.MarshalJSON,
+	} {
+		b, err := marshal(exp)
+		require.Nil(err)
+		got, err := model.Unmarshal(b)
+		require.Nil(err)
+		assert.Equal(exp, got)
+
+		// Marshaling the unmarshaled resources produces the same document
+		again, err := marshal(got)
+		require.Nil(err)
+		assert.Equal(string(b), string(again))
+	}
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name string
+		doc  string
+	}{
+		{
+			"missing version",
+			`
+resources:
+- kind:
+    name: Repository
+`,
+		},
+		{
+			"unsupported version",
+			`
+version: v0
+resources:
+- kind:
+    name: Repository
+`,
+		},
+		{
+			"field without definition",
+			`
+version: v1
+resources:
+- kind:
+    name: Repository
+  fields:
+    Name:
+      path: Name
+`,
+		},
+		{
+			"field path does not match key",
+			`
+version: v1
+resources:
+- kind:
+    name: Repository
+  fields:
+    Name:
+      path: RepositoryName
+      definition:
+        type: string
+`,
+		},
+	}
+	for _, test := range tests {
+		_, err := model.Unmarshal([]byte(test.doc))
+		assert.NotNil(err, test.name)
+	}
+}
+
+func TestUnmarshal_PathFromKey(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	rds, err := model.Unmarshal([]byte(`
+version: v1
+resources:
+- kind:
+    cloud_provider: aws
+    service: ecr
+    name: Repository
+    plural_name: Repositories
+  fields:
+    EncryptionConfiguration.KMSKey:
+      definition:
+        type: string
+`))
+	require.Nil(err)
+	require.Len(rds, 1)
+	f := rds[0].GetField(fieldpath.FromString("EncryptionConfiguration.KMSKey"))
+	require.NotNil(f)
+	assert.Equal("EncryptionConfiguration.KMSKey", f.Path.String())
+	assert.Equal("KMSKey", f.Path.Back())
+	assert.Equal(schema.FieldTypeString, f.Definition.Type)
+}
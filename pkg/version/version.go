@@ -0,0 +1,17 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package version
+
+// Version is the version of grm-generate. It is set at build time with:
+//
+// go build -ldflags "-X github.com/anydotcloud/grm-generate/pkg/version.Version=v0.1.0"
+var Version = "dev"
@@ -1,4 +1,7 @@
 {{- define "boilerplate" -}}
+{{- if header -}}
+{{ header }}
+{{- else -}}
 // Licensed under the Apache License, Version 2.0 (the "License"). You may
 // not use this file except in compliance with the License. A copy of the
 // License is located at
@@ -9,6 +12,7 @@
 // on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
 // express or implied. See the License for the specific language governing
 // permissions and limitations under the License.
+{{- end }}
 
 // Code generated by grm-generate. DO NOT EDIT.
 {{- end -}}
@@ -0,0 +1,76 @@
+{{- template "boilerplate" }}
+
+package {{ .Version }}
+
+import (
+{{- if .ImportsAWS }}
+	"github.com/aws/aws-sdk-go/aws"
+{{- end }}
+	svcsdk "{{ .SDKPackage }}"
+{{- block "sdk.extra_imports" . }}{{ end }}
+)
+{{- range .Operations }}
+
+// New{{ .Type }}Input returns the input to the {{ .Name }} API operation built
+// from the resource's field values
+func (r *{{ $.Kind.Name }}) New{{ .Type }}Input() *svcsdk.{{ .InputType }} {
+	res := &svcsdk.{{ .InputType }}{}
+{{- range .Inputs }}{{ template "sdk_field" . }}{{- end }}
+	return res
+}
+
+// SetFrom{{ .Type }}Output copies the members of the output of the
+// {{ .Name }} API operation into the resource's fields
+func (r *{{ $.Kind.Name }}) SetFrom{{ .Type }}Output(out *svcsdk.{{ .OutputType }}) error {
+{{- if .OutputWrapper }}
+	if out == nil || out.{{ .OutputWrapper }} == nil {
+		return nil
+	}
+{{- else }}
+	if out == nil {
+		return nil
+	}
+{{- end }}
+{{- range .Outputs }}{{ template "sdk_field" . }}{{- end }}
+	return nil
+}
+{{- end }}
+{{- block "sdk.extra_methods" . }}{{ end }}
+
+{{- define "sdk_struct" }}
+		{{ .StructResult }} := &{{ .Type }}{}
+{{- range .Members }}{{ template "sdk_field" . }}{{- end }}
+{{- end }}
+
+{{- define "sdk_field" }}
+	if {{ .Var }} := {{ .Source }}; {{ .Var }} != nil {
+{{- if eq .Copy "struct" }}
+{{- template "sdk_struct" . }}
+{{- else if eq .Copy "list" }}
+		{{ .Result }} := make([]*{{ .Type }}, 0, len({{ .Var }}))
+		for _, {{ .Elem }} := range {{ .Var }} {
+			if {{ .Elem }} == nil {
+				continue
+			}
+{{- template "sdk_struct" . }}
+			{{ .Result }} = append({{ .Result }}, {{ .StructResult }})
+		}
+{{- else if eq .Copy "map" }}
+		{{ .Result }} := make(map[string]*{{ .Type }}, len({{ .Var }}))
+		for {{ .Key }}, {{ .Elem }} := range {{ .Var }} {
+			if {{ .Elem }} == nil {
+				continue
+			}
+{{- template "sdk_struct" . }}
+			{{ .Result }}[{{ .Key }}] = {{ .StructResult }}
+		}
+{{- end }}
+{{- if .IsSetter }}
+		if err := {{ .Target }}({{ .Value }}); err != nil {
+			return err
+		}
+{{- else }}
+		{{ .Target }} = {{ .Value }}
+{{- end }}
+	}
+{{- end }}
@@ -0,0 +1,93 @@
+{{- template "boilerplate" }}
+
+package {{ .Version }}
+
+import (
+{{- if .ImportsReflect }}
+	"reflect"
+{{ end }}
+	"github.com/anydotcloud/grm/pkg/compare"
+	"github.com/anydotcloud/grm/pkg/types/resource"
+{{- block "delta.extra_imports" . }}{{ end }}
+)
+
+// Delta returns a Delta object containing the difference between this
+// Resource and another. Read-only fields are not compared.
+func (r *{{ .Kind.Name }}) Delta(other resource.Resource) *compare.Delta {
+	delta := compare.NewDelta()
+	b, ok := other.(*{{ .Kind.Name }})
+	if !ok {
+		delta.Add("", r, other)
+		return delta
+	}
+	a := r
+{{- range .Fields }}
+{{ template "delta_field" . }}
+{{- end }}
+{{- block "delta.extra_comparisons" . }}{{ end }}
+	return delta
+}
+{{- if .ImportsUnordered }}
+
+// equalIgnoringOrder returns true if the supplied slices contain equal
+// elements regardless of order
+func equalIgnoringOrder(a interface{}, b interface{}) bool {
+	va := reflect.ValueOf(a)
+	vb := reflect.ValueOf(b)
+	if va.Len() != vb.Len() {
+		return false
+	}
+	matched := make([]bool, vb.Len())
+	for i := 0; i < va.Len(); i++ {
+		found := false
+		for j := 0; j < vb.Len(); j++ {
+			if !matched[j] && reflect.DeepEqual(
+				va.Index(i).Interface(), vb.Index(j).Interface(),
+			) {
+				matched[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+{{- end }}
+{{- block "delta.extra_methods" . }}{{ end }}
+
+{{- define "delta_field" }}
+{{- if eq .Compare "scalar" }}
+	if compare.HasNilDifference({{ .A }}, {{ .B }}) {
+		delta.Add("{{ .Path }}", {{ .A }}, {{ .B }})
+	} else if {{ .A }} != nil && *{{ .A }} != *{{ .B }} {
+		delta.Add("{{ .Path }}", {{ .A }}, {{ .B }})
+	}
+{{- else if eq .Compare "time" }}
+	if compare.HasNilDifference({{ .A }}, {{ .B }}) {
+		delta.Add("{{ .Path }}", {{ .A }}, {{ .B }})
+	} else if {{ .A }} != nil && !{{ .A }}.Equal(*{{ .B }}) {
+		delta.Add("{{ .Path }}", {{ .A }}, {{ .B }})
+	}
+{{- else if eq .Compare "struct" }}
+	if compare.HasNilDifference({{ .A }}, {{ .B }}) {
+		delta.Add("{{ .Path }}", {{ .A }}, {{ .B }})
+	} else if {{ .A }} != nil {
+{{- range .Members }}{{ template "delta_field" . }}{{- end }}
+	}
+{{- else if eq .Compare "set" }}
+	if !equalIgnoringOrder({{ .A }}, {{ .B }}) {
+		delta.Add("{{ .Path }}", {{ .A }}, {{ .B }})
+	}
+{{- else if eq .Compare "collection" }}
+	if (len({{ .A }}) != 0 || len({{ .B }}) != 0) && !reflect.DeepEqual({{ .A }}, {{ .B }}) {
+		delta.Add("{{ .Path }}", {{ .A }}, {{ .B }})
+	}
+{{- else }}
+	if !reflect.DeepEqual({{ .A }}, {{ .B }}) {
+		delta.Add("{{ .Path }}", {{ .A }}, {{ .B }})
+	}
+{{- end }}
+{{- end }}
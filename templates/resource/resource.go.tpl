@@ -3,21 +3,26 @@
 package {{ .Version }}
 
 import (
-    "github.com/anydotcloud/grm/pkg/compare"
+    "strings"
+
     grmerr "github.com/anydotcloud/grm/pkg/error"
     "github.com/anydotcloud/grm/pkg/path/fieldpath"
     "github.com/anydotcloud/grm/pkg/types/resource"
     "github.com/anydotcloud/grm/pkg/types/resource/schema"
 
     resschema "{{ .ResourceSchemaPackage }}"
+{{- block "resource.extra_imports" . }}{{ end }}
 )
 
 {{ .Documentation }}
 type {{ .Kind.Name }} struct {
     values map[string]interface{}
     errors []error
+{{- block "resource.extra_fields" . }}{{ end }}
 }
 
+var _ resource.Resource = &{{ .Kind.Name }}{}
+
 // New returns a pointer to a new {{ .Kind.Name }}
 func New() *{{ .Kind.Name }} {
     return &{{ .Kind.Name }}{
@@ -33,22 +38,54 @@ func New() *{{ .Kind.Name }} {
 // would return a non-empty set of errors that placed the resource into an
 // invalid state.
 func (r *{{ .Kind.Name }}) IsValid() bool {
+{{- if .Status.FailedCases }}
+    switch r.status() {
+    case {{ .Status.FailedCases }}:
+        return false
+    }
+{{- end }}
     return len(r.errors) == 0
 }
 
 // IsReady returns true if the resource's state indicates that the resource
-// is "active", "available" or "ready".
+// is "active", "available" or "ready". A resource without a status field is
+// ready as soon as it exists.
 func (r *{{ .Kind.Name }}) IsReady() bool {
-    // TODO(jaypipes)
+{{- if .Status.ReadyCases }}
+    switch r.status() {
+    case {{ .Status.ReadyCases }}:
+        return true
+    }
     return false
+{{- else }}
+    return true
+{{- end }}
 }
 
 // IsImmutable returns true if the resource's state indicates that the resource
 // may NOT be modified.
 func (r *{{ .Kind.Name }}) IsImmutable() bool {
-    // TODO(jaypipes)
+{{- if .Status.TerminalCases }}
+    switch r.status() {
+    case {{ .Status.TerminalCases }}:
+        return true
+    }
+{{- end }}
     return false
 }
+{{- if .Status.Value }}
+
+// status returns the value of the {{ .Status.Path }} field, or the empty string
+// if the field is not set
+func (r *{{ .Kind.Name }}) status() string {
+{{- range .Status.Guards }}
+    if {{ . }} == nil {
+        return ""
+    }
+{{- end }}
+    return *{{ .Status.Value }}
+}
+{{- end }}
 
 // Errors returns zero or more errors that indicate why the resource may be
 // in an invalid state.
@@ -59,20 +96,13 @@ func (r *{{ .Kind.Name }}) Errors() []error {
 // Identifiers returns an Identifiers which contain all the information
 // needed to identify the resource.
 func (r *{{ .Kind.Name }}) Identifiers() resource.Identifiers {
-    return resschema.Identifiers,
+    return &identifiers{r: r}
 }
 
 // Schema returns a Schema that describes the resource's fields and
 // identifiers
 func (r *{{ .Kind.Name }}) Schema() schema.Schema {
-    return resschema.Schema,
-}
-
-// Delta returns a Delta object containing the difference between this
-// Resource and another.
-func (r *{{ .Kind.Name }}) Delta(other resource.Resource) *compare.Delta {
-    // TODO(jaypipes)
-    return nil
+    return resschema.Schema
 }
 
 // Values returns a map, keyed by stringified field path, of field
@@ -103,7 +133,7 @@ func (r *{{ .Kind.Name }}) ValueAt(p *fieldpath.Path) (interface{}, bool) {
 // Note that the field path is searched in a case-insensitive fashion. If there
 // is no such field at the supplied path, returns an error.
 func (r *{{ .Kind.Name }}) SetAt(p *fieldpath.Path, val interface{}) error {
-    for fp := r.values {
+    for fp := range resschema.Schema.Fields() {
         if strings.EqualFold(fp, p.String()) {
             r.values[fp] = val
             return nil
@@ -111,3 +141,59 @@ func (r *{{ .Kind.Name }}) SetAt(p *fieldpath.Path, val interface{}) error {
     }
     return grmerr.UnknownFieldAtPath(p.String())
 }
+
+// identifiers describes the values of a {{ .Kind.Name }}'s identifying fields.
+// The identifying fields are described in the resource's schema.
+type identifiers struct {
+    r *{{ .Kind.Name }}
+}
+
+// ValuesIter returns a slice, ordered by efficiency of fetch operation, of
+// maps, keyed by identifying field, of identifying field values. Sets of
+// identifying fields that are not all set are skipped.
+func (i *identifiers) ValuesIter() []map[schema.Field]string {
+    res := []map[schema.Field]string{}
+    for _, fields := range resschema.Schema.Identifiers().Fields() {
+        values := map[schema.Field]string{}
+        for _, f := range fields {
+            v, ok := i.value(f)
+            if !ok {
+                values = nil
+                break
+            }
+            values[f] = v
+        }
+        if values != nil {
+            res = append(res, values)
+        }
+    }
+    return res
+}
+
+// ValuesBy returns a slice of strings representing the values of supplied
+// identifying Fields. Fields that are not set have an empty string value.
+func (i *identifiers) ValuesBy(fields ...schema.Field) []string {
+    res := make([]string, len(fields))
+    for x, f := range fields {
+        res[x], _ = i.value(f)
+    }
+    return res
+}
+
+// value returns the string value of the supplied field and whether the field
+// is set
+func (i *identifiers) value(f schema.Field) (string, bool) {
+    for fp, sf := range resschema.Schema.Fields() {
+        if sf != f {
+            continue
+        }
+        v, ok := i.r.ValueAt(fieldpath.FromString(fp))
+        if !ok {
+            return "", false
+        }
+        s, ok := v.(string)
+        return s, ok
+    }
+    return "", false
+}
+{{- block "resource.extra_methods" . }}{{ end }}
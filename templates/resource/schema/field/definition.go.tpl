@@ -3,14 +3,11 @@
 package field
 
 import (
-	"github.com/anydotcloud/grm/pkg/types/resource"
 	"github.com/anydotcloud/grm/pkg/types/resource/schema"
-
-	res "{{ .ResourcePackage }}"
 )
 
 var (
-    memberFields{{ .Name }} = map[string]resource.Field{
+    memberFields{{ .Name }} = map[string]schema.Field{
 {{- range $memberFieldName, $memberFieldTypeName := .MemberFields }}
         "{{ $memberFieldName }}": {{ $memberFieldTypeName }},
 {{ end -}}
@@ -60,28 +57,28 @@ func (d *def{{ .Name }}) MemberFields() map[string]schema.Field {
 
 // IsRequired returns true if the field is required to be set by the user
 func (d *def{{ .Name }}) IsRequired() bool {
-	return {{ printf "%b" .IsRequired }}
+	return {{ printf "%t" .IsRequired }}
 }
 
 // IsReadOnly returns true if the field is not settable by the user
 func (d *def{{ .Name }}) IsReadOnly() bool {
-	return {{ printf "%b" .IsReadOnly }}
+	return {{ printf "%t" .IsReadOnly }}
 }
 
 // IsImmutable returns true if the field cannot be changed once set
 func (d *def{{ .Name }}) IsImmutable() bool {
-	return {{ printf "%b" .IsImmutable }}
+	return {{ printf "%t" .IsImmutable }}
 }
 
 // IsLateInitialized returns true if the field is "late initialized"
 // with a service-side default value
 func (d *def{{ .Name }}) IsLateInitialized() bool {
-	return {{ printf "%b" .IsLateInitialized }}
+	return {{ printf "%t" .IsLateInitialized }}
 }
 
 // IsSecret returns true if the field contains secret information
 func (d *def{{ .Name }}) IsSecret() bool {
-	return {{ printf "%b" .IsSecret }}
+	return {{ printf "%t" .IsSecret }}
 }
 
 // References returns the Kind for a referred type if the field contains a
@@ -92,10 +89,10 @@ func (d *def{{ .Name }}) IsSecret() bool {
 // this field would be FieldTypeList. The ElementType() of this field would
 // be FieldTypeString. The References() of this field would return a Kind
 // containing "ec2.aws/Subnet".
-func (d *def{{ .Name }}) References() resource.Kind {
+func (d *def{{ .Name }}) References() schema.Kind {
     // TODO(jaypipes)
 	return nil
 }
 
 {{ .Documentation }}
-var {{ .Name }} resource.Field = &def{{ .Name }}{}
+var {{ .Name }} schema.Field = &def{{ .Name }}{}
@@ -6,8 +6,11 @@ import (
     "strings"
 
     "github.com/anydotcloud/grm/pkg/path/fieldpath"
-	"github.com/anydotcloud/grm/pkg/types/resource"
 	"github.com/anydotcloud/grm/pkg/types/resource/schema"
+{{- if .Fields }}
+
+	"{{ .FieldPackage }}"
+{{- end }}
 )
 
 var (
@@ -18,13 +21,13 @@ var (
     }
 )
 
-type schema struct{
+type resourceSchema struct{
     schema.Kind
 }
 
 // Field returns a Field at a given field path, or nil if there is no Field
 // at that path.
-func (s *schema) Field(p *fieldpath.Path) schema.Field {
+func (s *resourceSchema) Field(p *fieldpath.Path) schema.Field {
     for pathStr, f := range schemaFields {
         if strings.EqualFold(pathStr, p.String()) {
             return f
@@ -35,15 +38,27 @@ func (s *schema) Field(p *fieldpath.Path) schema.Field {
 
 // Fields returns a map, keyed by field path string, of Fields that
 // describe the resource's member fields.
-func (s *schema) Fields() map[string]schema.Field {
+func (s *resourceSchema) Fields() map[string]schema.Field {
     return schemaFields
 }
 
 // Identifiers returns information about a resource's identifying fields
 // and those fields' values.
-Identifiers() schema.Identifiers {
+func (s *resourceSchema) Identifiers() schema.Identifiers {
     return Identifiers
 }
 
+type identifiers struct{}
+
+// Fields returns a slice, ordered by efficiency of fetch operation, of sets
+// of Fields that together identify a resource. No identifying fields are
+// discovered yet.
+func (i *identifiers) Fields() [][]schema.Field {
+    return [][]schema.Field{}
+}
+
+// Identifiers describes the resource's identifying fields.
+var Identifiers schema.Identifiers = &identifiers{}
+
 // Schema contains methods that returns information about a resource's schema.
-var Schema schema.Schema = &schema{Kind}
+var Schema schema.Schema = &resourceSchema{Kind}
@@ -0,0 +1,73 @@
+{{- template "boilerplate" }}
+
+package {{ .Version }}
+
+import (
+{{- if .Accessors }}
+	"encoding/json"
+{{- end }}
+{{- if .ImportsTime }}
+	"time"
+{{ end }}
+{{- if .Accessors }}
+	"github.com/anydotcloud/grm/pkg/path/fieldpath"
+{{- end }}
+{{- block "types.extra_imports" . }}{{ end }}
+)
+{{- range .Structs }}
+
+{{ .Documentation }}
+type {{ .Name }} struct {
+{{- range .Members }}
+	{{ .Name }} {{ .Type }} `json:"{{ .JSONName }},omitempty"`
+{{- end }}
+}
+{{- end }}
+{{- range .Accessors }}
+
+// Get{{ .Name }} returns the value of the {{ .Path }} field, or nil if the
+// field is not set. The value is read with ValueAt. A value set with SetAt
+// that is not a {{ .Type }} is converted, in which case changes to the
+// returned value do not change the field. Returns nil if the value cannot be
+// converted.
+func (r *{{ $.Kind.Name }}) Get{{ .Name }}() {{ if .IsScalar }}*{{ end }}{{ .Type }} {
+	v, ok := r.ValueAt(fieldpath.FromString("{{ .Path }}"))
+	if !ok {
+		return nil
+	}
+	tv, ok := v.({{ .Type }})
+	if !ok && !convertValue(v, &tv) {
+		return nil
+	}
+{{- if .IsScalar }}
+	return &tv
+{{- else }}
+	return tv
+{{- end }}
+}
+
+// Set{{ .Name }} sets the value of the {{ .Path }} field. The value is
+// written with SetAt.
+func (r *{{ $.Kind.Name }}) Set{{ .Name }}(v {{ .Type }}) error {
+	return r.SetAt(fieldpath.FromString("{{ .Path }}"), v)
+}
+{{- end }}
+{{- if .Accessors }}
+
+// convertValue converts the supplied value, of a Go type other than the type
+// of the value pointed to by the supplied pointer, by encoding it as JSON and
+// decoding it into the pointed to value. For example, an int is converted to
+// an int64 and a map[string]interface{} to a struct. Returns false if the
+// value is nil or cannot be converted.
+func convertValue(v interface{}, ptr interface{}) bool {
+	if v == nil {
+		return false
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(b, ptr) == nil
+}
+{{- end }}
+{{- block "types.extra_methods" . }}{{ end }}
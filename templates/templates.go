@@ -0,0 +1,22 @@
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package templates contains the default Go templates, embedded in the
+// grm-generate binary so that an installed binary needs no files on disk.
+package templates
+
+import "embed"
+
+// FS contains the default Go templates. Paths are relative to this
+// directory, e.g. "resource/resource.go.tpl".
+//
+//go:embed boilerplate.go.tpl resource
+var FS embed.FS